@@ -0,0 +1,286 @@
+// Package bundle packages an edited image with everything a verifier needs besides
+// the public parameters: the proof chain, the camera's public key and references to the
+// verifying keys of each proof. Bundles are written to single .pgk files.
+package bundle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+// Version of the bundle file format.
+const Version = 2
+
+// Extension of bundle files.
+const Extension = ".pgk"
+
+// First bytes of a bundle file.
+var magic = [4]byte{'P', 'G', 'K', 'B'}
+
+// The verifying key a proof of the bundle was made for.
+type VKRef struct {
+	Transformation string `json:"transformation"`
+	Curve          string `json:"curve"`
+	Fingerprint    []byte `json:"fingerprint"`
+}
+
+type Bundle struct {
+	Image     image.Image
+	Chain     circuits.ProofChain
+	PublicKey signature.PublicKey // The camera's public key, shared by every proof of the chain
+	VKs       []VKRef             // One reference per proof of the chain
+}
+
+// Bundle img with the chain of proofs of its history.
+func New(img image.Image, chain circuits.ProofChain) (Bundle, error) {
+	last, err := chain.Last()
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	bundle := Bundle{Image: img, Chain: chain, PublicKey: last.VK.PublicKey}
+	for _, proof := range chain.Proofs {
+		if proof.VK.VeriKey == nil {
+			return Bundle{}, errors.New("PROOF HAS NO VERIFYING KEY")
+		}
+
+		fingerprint, err := circuits.VKFingerprint(proof.VK.VeriKey)
+		if err != nil {
+			return Bundle{}, err
+		}
+
+		bundle.VKs = append(bundle.VKs, VKRef{
+			Transformation: proof.Transformation,
+			Curve:          proof.VK.VeriKey.CurveID().String(),
+			Fingerprint:    fingerprint,
+		})
+	}
+
+	return bundle, nil
+}
+
+// Verify the proof chain of the bundle against the bundled image, with the verifying keys
+// of the public parameters.
+func (bundle Bundle) Verify(vks circuits.VerifyingKeys, trustedKeys []signature.PublicKey) (bool, error) {
+	return circuits.VerifyChain(bundle.Chain, bundle.Image, vks, trustedKeys)
+}
+
+// Write the bundle: the magic bytes and version, then the length-prefixed image, curve of the
+// camera key, camera key, verifying key references and proofs, then the SHA-256 of all of it.
+func Write(w io.Writer, bundle Bundle) error {
+	encodedImage := bundle.Image.ToByte()
+	if len(encodedImage) == 0 {
+		return errors.New("COULD NOT ENCODE THE BUNDLED IMAGE")
+	}
+
+	data, err := encode(bundle, encodedImage)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// Read a bundle written by Write. vks are the verifying keys of the public parameters,
+// every proof of the bundle must reference one of them.
+func Read(r io.Reader, vks ...groth16.VerifyingKey) (Bundle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	bundle, encodedImage, err := decode(data, vks)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	if bundle.Image, err = image.FromByte(encodedImage); err != nil {
+		return Bundle{}, err
+	}
+
+	return bundle, nil
+}
+
+// Encode everything but the image of the bundle, which is given already encoded.
+func encode(bundle Bundle, encodedImage []byte) ([]byte, error) {
+	if bundle.PublicKey == nil || len(bundle.Chain.Proofs) == 0 || len(bundle.VKs) != len(bundle.Chain.Proofs) {
+		return nil, errors.New("INCOMPLETE BUNDLE")
+	}
+
+	vkRefs, err := json.Marshal(bundle.VKs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The camera key is on the curve the steps of the chain sign on
+	keyCurve := circuits.SigningCurve(bundle.Chain.Proofs[0].VK.VeriKey.CurveID())
+
+	var buf bytes.Buffer
+	buf.Write(magic[:])
+	binary.Write(&buf, binary.BigEndian, uint16(Version))
+	writeSection(&buf, encodedImage)
+	writeSection(&buf, []byte(keyCurve.String()))
+	writeSection(&buf, bundle.PublicKey.Bytes())
+	writeSection(&buf, vkRefs)
+
+	binary.Write(&buf, binary.BigEndian, uint32(len(bundle.Chain.Proofs)))
+	for _, proof := range bundle.Chain.Proofs {
+		encodedProof, err := circuits.MarshalProof(proof)
+		if err != nil {
+			return nil, err
+		}
+		writeSection(&buf, encodedProof)
+	}
+
+	checksum := sha256.Sum256(buf.Bytes())
+	buf.Write(checksum[:])
+
+	return buf.Bytes(), nil
+}
+
+// Decode the data written by encode, returning the bundle without its image and the encoded image.
+func decode(data []byte, vks []groth16.VerifyingKey) (Bundle, []byte, error) {
+	// Check the integrity of the whole file before parsing it
+	if len(data) < len(magic)+sha256.Size {
+		return Bundle{}, nil, errors.New("NOT A BUNDLE")
+	}
+	content, checksum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if sum := sha256.Sum256(content); !bytes.Equal(sum[:], checksum) {
+		return Bundle{}, nil, errors.New("BUNDLE CHECKSUM MISMATCH")
+	}
+
+	reader := bytes.NewReader(content)
+	var fileMagic [4]byte
+	if _, err := io.ReadFull(reader, fileMagic[:]); err != nil || fileMagic != magic {
+		return Bundle{}, nil, errors.New("NOT A BUNDLE")
+	}
+
+	var version uint16
+	if err := binary.Read(reader, binary.BigEndian, &version); err != nil {
+		return Bundle{}, nil, err
+	}
+	if version != Version {
+		return Bundle{}, nil, fmt.Errorf("UNSUPPORTED BUNDLE VERSION %d", version)
+	}
+
+	var err error
+	sections := make([][]byte, 4)
+	for i := range sections {
+		if sections[i], err = readSection(reader); err != nil {
+			return Bundle{}, nil, err
+		}
+	}
+
+	var bundle Bundle
+	keyCurve, err := ecc.IDFromString(string(sections[1]))
+	if err != nil {
+		return Bundle{}, nil, err
+	}
+	if bundle.PublicKey, err = circuits.NewPublicKey(keyCurve, sections[2]); err != nil {
+		return Bundle{}, nil, err
+	}
+
+	if err := json.Unmarshal(sections[3], &bundle.VKs); err != nil {
+		return Bundle{}, nil, err
+	}
+
+	var nbProofs uint32
+	if err := binary.Read(reader, binary.BigEndian, &nbProofs); err != nil {
+		return Bundle{}, nil, err
+	}
+	if int(nbProofs) != len(bundle.VKs) {
+		return Bundle{}, nil, errors.New("BUNDLE HAS ONE VERIFYING KEY REFERENCE PER PROOF")
+	}
+
+	for i := range bundle.VKs {
+		section, err := readSection(reader)
+		if err != nil {
+			return Bundle{}, nil, err
+		}
+
+		var encoded circuits.EncodedProof
+		if err := encoded.UnmarshalBinary(section); err != nil {
+			return Bundle{}, nil, err
+		}
+
+		// Each proof is made for the verifying key the bundle references, with the bundled camera key
+		ref := bundle.VKs[i]
+		if encoded.Transformation != ref.Transformation || encoded.Curve != ref.Curve || !bytes.Equal(encoded.VKFingerprint, ref.Fingerprint) {
+			return Bundle{}, nil, fmt.Errorf("PROOF %d DOES NOT MATCH ITS VERIFYING KEY REFERENCE", i)
+		}
+		if !bytes.Equal(encoded.PublicKey, sections[2]) {
+			return Bundle{}, nil, fmt.Errorf("PROOF %d IS NOT FROM THE BUNDLED CAMERA KEY", i)
+		}
+
+		proof, err := encoded.Decode(vks...)
+		if err != nil {
+			return Bundle{}, nil, err
+		}
+		bundle.Chain.Append(proof)
+	}
+
+	if reader.Len() != 0 {
+		return Bundle{}, nil, errors.New("TRAILING BYTES IN BUNDLE")
+	}
+
+	return bundle, sections[0], nil
+}
+
+// Write the bundle to the file at path.
+func WriteFile(path string, bundle Bundle) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, bundle); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Read the bundle in the file at path.
+func ReadFile(path string, vks ...groth16.VerifyingKey) (Bundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Bundle{}, err
+	}
+	defer file.Close()
+
+	return Read(file, vks...)
+}
+
+// Write a big-endian uint32 length followed by the section.
+func writeSection(buf *bytes.Buffer, section []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(section)))
+	buf.Write(section)
+}
+
+// Read a section written by writeSection.
+func readSection(reader *bytes.Reader) ([]byte, error) {
+	var length uint32
+	if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	if int64(length) > int64(reader.Len()) {
+		return nil, errors.New("TRUNCATED BUNDLE")
+	}
+
+	section := make([]byte, length)
+	if _, err := io.ReadFull(reader, section); err != nil {
+		return nil, err
+	}
+
+	return section, nil
+}
@@ -0,0 +1,213 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"slices"
+	"testing"
+
+	"src/circuits"
+	"src/image"
+	"src/transformations"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+// A circuit small enough to run a real setup in tests.
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+// Return a bundle whose chain holds two proofs of the square circuit, and their verifying key.
+// Its chain does not verify, it only exercises the encoding.
+func newTestBundle(assert *test.Assert) (Bundle, groth16.VerifyingKey) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	fullWitness, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	pcdProof, err := groth16.Prove(ccs, pk, fullWitness)
+	assert.NoError(err)
+
+	img, err := image.NewImage("random")
+	assert.NoError(err)
+	sk, err := circuits.NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+	proof, err := circuits.NewCaptureProof(img, sk, ecc.BN254)
+	assert.NoError(err)
+	proof.PCD_Proof, proof.VK.VeriKey, proof.Transformation = pcdProof, vk, "identity"
+
+	bundle, err := New(img, circuits.ProofChain{Proofs: []circuits.Proof{proof, proof}})
+	assert.NoError(err)
+
+	return bundle, vk
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	assert := test.NewAssert(t)
+	written, vk := newTestBundle(assert)
+
+	var buf bytes.Buffer
+	assert.NoError(Write(&buf, written))
+	data := buf.Bytes()
+
+	read, err := Read(bytes.NewReader(data), vk)
+	assert.NoError(err)
+	assert.Equal(written.Image, read.Image)
+	assert.Equal(written.VKs, read.VKs)
+	assert.True(read.PublicKey.Equal(written.PublicKey))
+	assert.Equal(2, len(read.Chain.Proofs))
+
+	// The bundled image keeps the metadata its digest is computed from
+	digest, err := read.Image.Digest(ecc.BN254)
+	assert.NoError(err)
+	assert.Equal(written.Chain.Proofs[0].Origin_Digest, digest)
+
+	// A verifier without the verifying key cannot read the bundle
+	_, err = Read(bytes.NewReader(data))
+	assert.Error(err)
+
+	// Nor can it read a modified bundle
+	data[len(data)/2]++
+	_, err = Read(bytes.NewReader(data), vk)
+	assert.Error(err)
+}
+
+// Return the first chunk of the given type of a PNG file, length and CRC included.
+func findChunk(file []byte, chunkType string) ([]byte, error) {
+	for offset := len(pngSignature); offset+12 <= len(file); {
+		length := int(binary.BigEndian.Uint32(file[offset:]))
+		if string(file[offset+4:offset+8]) == chunkType {
+			return file[offset : offset+12+length], nil
+		}
+		offset += 12 + length
+	}
+
+	return nil, errors.New("CHUNK NOT FOUND")
+}
+
+func TestPNGRoundTrip(t *testing.T) {
+	assert := test.NewAssert(t)
+	written, vk := newTestBundle(assert)
+
+	var buf bytes.Buffer
+	assert.NoError(WritePNG(&buf, written))
+	data := buf.Bytes()
+
+	// Any PNG decoder reads the pixels
+	decoded, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(err)
+	assert.Equal(image.N, decoded.Bounds().Dx())
+
+	read, err := ReadPNG(bytes.NewReader(data), vk)
+	assert.NoError(err)
+	assert.Equal(written.Image, read.Image)
+	assert.Equal(written.VKs, read.VKs)
+	assert.Equal(2, len(read.Chain.Proofs))
+
+	// So does a copy re-encoded with a palette, which keeps the bundle chunk
+	paletted := stdimage.NewPaletted(decoded.Bounds(), nil)
+	for _, pixel := range written.Image.Pixels {
+		c := color.RGBA{R: pixel.R, G: pixel.G, B: pixel.B, A: 255}
+		if !slices.Contains(paletted.Palette, color.Color(c)) {
+			paletted.Palette = append(paletted.Palette, c)
+		}
+	}
+	draw.Draw(paletted, paletted.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+	var reencoded bytes.Buffer
+	assert.NoError(png.Encode(&reencoded, paletted))
+	chunk, err := findChunk(data, "iTXt")
+	assert.NoError(err)
+	iend := reencoded.Len() - 12
+	copied := append(append(append([]byte{}, reencoded.Bytes()[:iend]...), chunk...), reencoded.Bytes()[iend:]...)
+
+	decoded, err = png.Decode(bytes.NewReader(copied))
+	assert.NoError(err)
+	_, isRGBA := decoded.(*stdimage.RGBA)
+	assert.False(isRGBA)
+
+	read, err = ReadPNG(bytes.NewReader(copied), vk)
+	assert.NoError(err)
+	assert.Equal(written.Image, read.Image)
+
+	// A modified chunk is refused
+	idx := bytes.Index(data, []byte(PNGKeyword))
+	data[idx+len(PNGKeyword)+10]++
+	_, err = ReadPNG(bytes.NewReader(data), vk)
+	assert.Error(err)
+}
+
+func TestVerifiedChainRoundTrip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := circuits.Setup(4, ecc.BN254.ScalarField(), "identity", "crop")
+	assert.NoError(err)
+	prover, err := circuits.NewProver(params)
+	assert.NoError(err)
+	vks := params.VerifyingKeys()
+
+	// A camera captures a picture, then an editor crops it and proves an identity edit
+	identity, err := circuits.NewCameraIdentity(params.Curve)
+	assert.NoError(err)
+	trustedKeys := []signature.PublicKey{identity.PublicKey}
+	img, err := image.NewImageOfSize("random", 2, 2)
+	assert.NoError(err)
+	proof, err := circuits.NewCaptureProof(img, identity.SecKey, params.Curve)
+	assert.NoError(err)
+
+	var chain circuits.ProofChain
+	for _, tr := range []transformations.Transformation{transformations.CropT{X0: 0, Y0: 1, X1: 1, Y1: 1}, transformations.IdentityT{}} {
+		proof, img, err = tr.TransformAndProve(prover, img, proof)
+		assert.NoError(err)
+		chain.Append(proof)
+	}
+
+	written, err := New(img, chain)
+	assert.NoError(err)
+	valid, err := written.Verify(vks, trustedKeys)
+	assert.NoError(err)
+	assert.True(valid)
+
+	// The chain still verifies once read back, from a bundle file or a PNG
+	var buf bytes.Buffer
+	assert.NoError(Write(&buf, written))
+	read, err := Read(bytes.NewReader(buf.Bytes()), vks.List()...)
+	assert.NoError(err)
+	valid, err = read.Verify(vks, trustedKeys)
+	assert.NoError(err)
+	assert.True(valid)
+
+	var pngBuf bytes.Buffer
+	assert.NoError(WritePNG(&pngBuf, written))
+	valid, err = VerifyPNG(bytes.NewReader(pngBuf.Bytes()), vks, trustedKeys)
+	assert.NoError(err)
+	assert.True(valid)
+
+	// But not with another camera key
+	other, err := circuits.NewCameraIdentity(params.Curve)
+	assert.NoError(err)
+	_, err = read.Verify(vks, []signature.PublicKey{other.PublicKey})
+	assert.Error(err)
+
+	// Nor once a pixel of the bundled image changed
+	read.Image.Pixels[0].R++
+	_, err = read.Verify(vks, trustedKeys)
+	assert.Error(err)
+}
@@ -0,0 +1,184 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image/png"
+	"io"
+
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+// Keyword of the iTXt chunk holding the bundle in PNG files.
+const PNGKeyword = "PhotoGnark"
+
+// First bytes of every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Write the bundled image as a PNG whose iTXt chunk holds the rest of the bundle: the proof chain,
+// camera key, verifying key references and image metadata. The pixels are only stored once, in the PNG.
+func WritePNG(w io.Writer, bundle Bundle) error {
+	rgba, err := bundle.Image.ToRGBA()
+	if err != nil {
+		return err
+	}
+
+	metadata, err := bundle.Image.Metadata.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	data, err := encode(bundle, metadata)
+	if err != nil {
+		return err
+	}
+
+	var encodedPNG bytes.Buffer
+	if err := png.Encode(&encodedPNG, rgba); err != nil {
+		return err
+	}
+
+	// The encoder ends the file with an empty IEND chunk, the bundle goes right before it
+	iend := encodedPNG.Len() - 12
+	if _, err := w.Write(encodedPNG.Bytes()[:iend]); err != nil {
+		return err
+	}
+	if _, err := w.Write(iTXtChunk(PNGKeyword, base64.StdEncoding.EncodeToString(data))); err != nil {
+		return err
+	}
+	_, err = w.Write(encodedPNG.Bytes()[iend:])
+	return err
+}
+
+// Read a PNG written by WritePNG, or re-encoded with its iTXt chunk kept. vks are the
+// verifying keys of the public parameters, every proof of the bundle must reference one of them.
+func ReadPNG(r io.Reader, vks ...groth16.VerifyingKey) (Bundle, error) {
+	file, err := io.ReadAll(r)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	text, err := findITXt(file, PNGKeyword)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	bundle, encodedMetadata, err := decode(data, vks)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(file))
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	// A PNG re-encoded by another tool may use another color model, changed pixels
+	// are caught by the digests of the proof chain
+	if bundle.Image, err = image.FromStd(decoded); err != nil {
+		return Bundle{}, err
+	}
+
+	// The embedded metadata must describe the pixels of the PNG
+	var metadata image.Metadata
+	if err := metadata.UnmarshalBinary(encodedMetadata); err != nil {
+		return Bundle{}, err
+	}
+	if metadata.Width != bundle.Image.Metadata.Width || metadata.Height != bundle.Image.Metadata.Height {
+		return Bundle{}, errors.New("PNG DIMENSIONS DO NOT MATCH THE EMBEDDED METADATA")
+	}
+	bundle.Image.Metadata = metadata
+
+	return bundle, nil
+}
+
+// Read a PNG written by WritePNG and verify its proof chain against its pixels.
+func VerifyPNG(r io.Reader, vks circuits.VerifyingKeys, trustedKeys []signature.PublicKey) (bool, error) {
+	bundle, err := ReadPNG(r, vks.List()...)
+	if err != nil {
+		return false, err
+	}
+
+	return bundle.Verify(vks, trustedKeys)
+}
+
+// Return an uncompressed iTXt chunk holding text under the given keyword.
+func iTXtChunk(keyword string, text string) []byte {
+	var data bytes.Buffer
+	data.WriteString(keyword)
+	data.Write([]byte{0, 0, 0}) // Keyword separator, no compression, no compression method
+	data.Write([]byte{0, 0})    // No language tag, no translated keyword
+	data.WriteString(text)
+
+	return pngChunk("iTXt", data.Bytes())
+}
+
+// Return a PNG chunk: the length of data, the chunk type, data and the CRC of type and data.
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// Return the text of the uncompressed iTXt chunk with the given keyword.
+func findITXt(file []byte, keyword string) (string, error) {
+	if !bytes.HasPrefix(file, pngSignature) {
+		return "", errors.New("NOT A PNG FILE")
+	}
+
+	prefix := append([]byte(keyword), 0)
+	for offset := len(pngSignature); offset+12 <= len(file); {
+		length := int(binary.BigEndian.Uint32(file[offset:]))
+		if length > len(file)-offset-12 {
+			return "", errors.New("TRUNCATED PNG CHUNK")
+		}
+
+		chunkType := string(file[offset+4 : offset+8])
+		data := file[offset+8 : offset+8+length]
+		crc := binary.BigEndian.Uint32(file[offset+8+length:])
+		offset += 12 + length
+
+		if chunkType == "IEND" {
+			break
+		}
+		if chunkType != "iTXt" || !bytes.HasPrefix(data, prefix) {
+			continue
+		}
+
+		if crc32.ChecksumIEEE(file[offset-length-8:offset-4]) != crc {
+			return "", fmt.Errorf("CORRUPTED %s CHUNK", keyword)
+		}
+
+		// Skip the compression flag and method, then the language tag and translated keyword
+		rest := data[len(prefix):]
+		if len(rest) < 2 || rest[0] != 0 {
+			return "", fmt.Errorf("COMPRESSED %s CHUNKS ARE NOT SUPPORTED", keyword)
+		}
+		rest = rest[2:]
+		for range 2 {
+			end := bytes.IndexByte(rest, 0)
+			if end < 0 {
+				return "", fmt.Errorf("INVALID %s CHUNK", keyword)
+			}
+			rest = rest[end+1:]
+		}
+
+		return string(rest), nil
+	}
+
+	return "", fmt.Errorf("NO %s CHUNK IN PNG", keyword)
+}
@@ -0,0 +1,80 @@
+package circuits
+
+import (
+	"math/big"
+	"src/image"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/cmp"
+)
+
+// The largest change of brightness a BrightnessCircuit accepts, in either direction.
+// Larger changes would wash out or black out the picture, which is no longer a permissible edit.
+const MaxBrightnessDelta = 64
+
+// This circuit proves that the output image is the input image with Delta added to every
+// channel of every pixel, each channel saturating at 0 and 255. Delta is a public parameter.
+type BrightnessCircuit struct {
+	PublicInputs
+	FrImage image.FrImage
+	Params  FrBrightnessT
+}
+
+func (circuit *BrightnessCircuit) Define(api frontend.API) error {
+	if err := circuit.AssertSignedInput(api, BrightnessCode, circuit.FrImage); err != nil {
+		return err
+	}
+
+	// Check that the change of brightness is allowed
+	circuit.CheckParams(api)
+
+	brightImage, err := circuit.Transform(api)
+	if err != nil {
+		return err
+	}
+
+	// Check that the adjusted image is the one committed to by the public output digest
+	return circuit.AssertOutput(api, brightImage)
+}
+
+func (circuit *BrightnessCircuit) CheckParams(api frontend.API) {
+	comparator := cmp.NewBoundedComparator(api, big.NewInt(2*MaxBrightnessDelta), false)
+
+	// Check that -MaxBrightnessDelta <= Delta <= MaxBrightnessDelta
+	comparator.AssertIsLessEq(-MaxBrightnessDelta, circuit.Params.Delta)
+	comparator.AssertIsLessEq(circuit.Params.Delta, MaxBrightnessDelta)
+}
+
+func (circuit *BrightnessCircuit) Transform(api frontend.API) (image.FrImage, error) {
+	// The adjusted image keeps the dimensions of the image
+	newImage := image.NewFrImage(len(circuit.FrImage.Pixels))
+	newImage.Width = circuit.FrImage.Width
+	newImage.Height = circuit.FrImage.Height
+
+	metadata, err := TransformMetadata(api, circuit.FrImage.Metadata, "brightness")
+	if err != nil {
+		return image.FrImage{}, err
+	}
+	newImage.Metadata = metadata
+
+	// With a bounded Delta, every channel plus Delta is between -MaxBrightnessDelta and 255 + MaxBrightnessDelta
+	comparator := cmp.NewBoundedComparator(api, big.NewInt(255+MaxBrightnessDelta), false)
+
+	for idx, pixel := range circuit.FrImage.Pixels {
+		r, g, b := UnpackRGB(api, pixel)
+		channels := []frontend.Variable{r, g, b}
+		for i, channel := range channels {
+			channels[i] = ClampChannel(api, comparator, api.Add(channel, circuit.Params.Delta))
+		}
+		newImage.Pixels[idx] = PackRGB(api, channels[0], channels[1], channels[2])
+	}
+
+	return newImage, nil
+}
+
+// Return the value clamped to [0, 255]. The comparator must bound the distance of the value to 0 and 255.
+func ClampChannel(api frontend.API, comparator *cmp.BoundedComparator, value frontend.Variable) frontend.Variable {
+	below := comparator.IsLess(value, 0)
+	above := comparator.IsLess(255, value)
+	return api.Select(below, 0, api.Select(above, 255, value))
+}
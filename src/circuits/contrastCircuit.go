@@ -0,0 +1,97 @@
+package circuits
+
+import (
+	"math/big"
+	"math/bits"
+	"src/image"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/cmp"
+)
+
+// The contrast of a channel c is changed by a factor Numerator/Denominator around ContrastPivot,
+// the new channel is ContrastPivot + (c - ContrastPivot) * Numerator / Denominator rounded to the
+// nearest integer, halves rounded up, then saturated at 0 and 255. The factor is at least
+// 1/MaxContrastFactor and at most MaxContrastFactor, the denominator at most MaxContrastDenominator.
+const (
+	ContrastPivot          = 128
+	MaxContrastFactor      = 4
+	MaxContrastDenominator = 256
+)
+
+// Offset added to the scaled channels before rounding, in units of the factor's denominator,
+// so that the dividend of the rounding is not negative: |c - ContrastPivot| * factor <= contrastOffset.
+const contrastOffset = ContrastPivot * MaxContrastFactor
+
+// This circuit proves that the output image is the input image with the contrast of every
+// channel of every pixel changed by the factor of the parameters, see ContrastPivot.
+// The numerator and denominator of the factor are public parameters.
+type ContrastCircuit struct {
+	PublicInputs
+	FrImage image.FrImage
+	Params  FrContrastT
+}
+
+func (circuit *ContrastCircuit) Define(api frontend.API) error {
+	if err := circuit.AssertSignedInput(api, ContrastCode, circuit.FrImage); err != nil {
+		return err
+	}
+
+	// Check that the factor is allowed
+	circuit.CheckParams(api)
+
+	contrastImage, err := circuit.Transform(api)
+	if err != nil {
+		return err
+	}
+
+	// Check that the adjusted image is the one committed to by the public output digest
+	return circuit.AssertOutput(api, contrastImage)
+}
+
+func (circuit *ContrastCircuit) CheckParams(api frontend.API) {
+	comparator := cmp.NewBoundedComparator(api, big.NewInt(MaxContrastFactor*MaxContrastFactor*MaxContrastDenominator), false)
+	numerator, denominator := circuit.Params.Numerator, circuit.Params.Denominator
+
+	// Check that 1 <= Denominator <= MaxContrastDenominator
+	comparator.AssertIsLessEq(1, denominator)
+	comparator.AssertIsLessEq(denominator, MaxContrastDenominator)
+
+	// Check that 1/MaxContrastFactor <= Numerator/Denominator <= MaxContrastFactor
+	comparator.AssertIsLessEq(numerator, api.Mul(denominator, MaxContrastFactor))
+	comparator.AssertIsLessEq(denominator, api.Mul(numerator, MaxContrastFactor))
+}
+
+func (circuit *ContrastCircuit) Transform(api frontend.API) (image.FrImage, error) {
+	// The adjusted image keeps the dimensions of the image
+	newImage := image.NewFrImage(len(circuit.FrImage.Pixels))
+	newImage.Width = circuit.FrImage.Width
+	newImage.Height = circuit.FrImage.Height
+
+	metadata, err := TransformMetadata(api, circuit.FrImage.Metadata, "contrast")
+	if err != nil {
+		return image.FrImage{}, err
+	}
+	newImage.Metadata = metadata
+
+	// The rounded channels are between ContrastPivot - contrastOffset and ContrastPivot + contrastOffset
+	comparator := cmp.NewBoundedComparator(api, big.NewInt(ContrastPivot+contrastOffset+255), false)
+
+	// Dividing by 2*Denominator: round((c - pivot) * N / D) = floor((2 * (c - pivot) * N + D) / (2 * D))
+	divisor := api.Mul(circuit.Params.Denominator, 2)
+	offset := api.Mul(divisor, contrastOffset)
+	quoBits := bits.Len(2*contrastOffset + 1)
+
+	for idx, pixel := range circuit.FrImage.Pixels {
+		r, g, b := UnpackRGB(api, pixel)
+		channels := []frontend.Variable{r, g, b}
+		for i, channel := range channels {
+			scaled := api.Mul(api.Sub(channel, ContrastPivot), circuit.Params.Numerator, 2)
+			rounded, _ := BoundedMod(api, api.Add(scaled, circuit.Params.Denominator, offset), divisor, quoBits, 2*MaxContrastDenominator)
+			channels[i] = ClampChannel(api, comparator, api.Add(rounded, ContrastPivot-contrastOffset))
+		}
+		newImage.Pixels[idx] = PackRGB(api, channels[0], channels[1], channels[2])
+	}
+
+	return newImage, nil
+}
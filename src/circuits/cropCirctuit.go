@@ -2,161 +2,50 @@ package circuits
 
 import (
 	"errors"
-	"fmt"
 	"math/big"
 	"src/image"
 
-	"github.com/consensys/gnark/constraint/solver"
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/std/algebra/native/twistededwards"
-	"github.com/consensys/gnark/std/hash/mimc"
-	"github.com/consensys/gnark/std/lookup/logderivlookup"
-	"github.com/consensys/gnark/std/signature/eddsa"
+	"github.com/consensys/gnark/std/math/cmp"
 )
 
-type Fr_SquareArea struct {
-	topLeft     Fr_Location
-	bottomRight Fr_Location
-}
-
-type Fr_Location struct {
-	X frontend.Variable
-	Y frontend.Variable
-}
-
 type CropCircuit struct {
-	PublicKey         eddsa.PublicKey   `gnark:",public"`
-	EdDSA_Signature   eddsa.Signature   `gnark:",public"`
-	ImageBytes        frontend.Variable // FrImage as a Big Endian
-	FrImage           image.FrImage
-	Transformed_Image image.FrImage `gnark:", public"`
-	Params            FrCropT
+	PublicInputs
+	FrImage image.FrImage
+	Params  FrCropT
 }
 
 func (circuit *CropCircuit) Define(api frontend.API) error {
-	// Verify the image has been signed
-	circuit.VerifySignature(api)
+	if err := circuit.AssertSignedInput(api, CropCode, circuit.FrImage); err != nil {
+		return err
+	}
 
 	// Check that params are within image bounds.
 	circuit.CheckParams(api)
 
 	// Check that the transformation & parameters are legal
 	// & tranform the image pixels
-	croppedImage := circuit.Transform(api)
-
-	fmt.Println("Out of CheckTransform")
-	img := logderivlookup.New(api)
-	for row := range image.N {
-		for col := range image.N {
-			flatIdx := row*image.N + col
-			img.Insert(circuit.FrImage.Pixels[flatIdx])
-		}
-	}
-
-	img2 := logderivlookup.New(api)
-	for row := range image.N {
-		for col := range image.N {
-			flatIdx := row*image.N + col
-			img2.Insert(croppedImage.Pixels[flatIdx])
-		}
-	}
-
-	// check if they equal the circuit's transformed_image
-	for row := 0; row < image.N; row++ {
-		for col := 0; col < image.N; col++ {
-			currentX := frontend.Variable(col)
-			currentY := frontend.Variable(row)
-
-			// Lookup the current pixel
-			currentFlatIdx := api.Add(api.Mul(currentY, circuit.Params.N), currentX)
-			currentPixel := img.Lookup(currentFlatIdx)[0]
-			currentPixel2 := img2.Lookup(currentFlatIdx)[0]
-
-			api.AssertIsEqual(currentPixel, currentPixel2)
-
-		}
-	}
-
-	fmt.Println("out")
-
-	return nil
-}
-
-func (circuit *CropCircuit) VerifySignature(api frontend.API) error {
-	// Create a new Twisted Edwards Curve
-	edCurve, err := twistededwards.NewEdCurve(api, 1)
-	if err != nil {
-		return err
-	}
-
-	// Create the MiMC hash function for Gnark circuits
-	mimc, err := mimc.NewMiMC(api)
+	croppedImage, err := circuit.Transform(api)
 	if err != nil {
 		return err
 	}
 
-	// Verify the signature against the Image bytes,
-	// using the public key, twisted edwards curve and hash function
-	eddsa.Verify(edCurve, circuit.EdDSA_Signature, circuit.ImageBytes, circuit.PublicKey, &mimc)
-
-	return nil
+	// Check that the cropped image is the one committed to by the public output digest
+	return circuit.AssertOutput(api, croppedImage)
 }
 
 func (circuit *CropCircuit) CheckParams(api frontend.API) {
-	nMinusOne := api.Sub(circuit.Params.N, 1)
-	// Check that params <= (N-1)
-	api.AssertIsLessOrEqual(circuit.Params.X0, nMinusOne)
-	api.AssertIsLessOrEqual(circuit.Params.Y0, nMinusOne)
-	api.AssertIsLessOrEqual(circuit.Params.X1, nMinusOne)
-	api.AssertIsLessOrEqual(circuit.Params.Y1, nMinusOne)
-
-	zero, _ := api.Compiler().ConstantValue(0)
-	// Check that params >= 0
-	api.AssertIsLessOrEqual(zero, circuit.Params.X0)
-	api.AssertIsLessOrEqual(zero, circuit.Params.Y0)
-	api.AssertIsLessOrEqual(zero, circuit.Params.X1)
-	api.AssertIsLessOrEqual(zero, circuit.Params.Y1)
-}
+	comparator := NewPixelComparator(api, circuit.FrImage)
 
-func WithinCropArea(api frontend.API, frRow, frCol frontend.Variable, cropArea Fr_SquareArea) frontend.Variable {
-	//	true if col >= X0 && row >= Y0
-	//		 && col <= X1 && row <= Y1
-	withinCropArea := api.And(
-		api.And(
-			api.Or(
-				api.IsZero(api.Cmp(
-					frCol,
-					cropArea.topLeft.X,
-				)),
-				api.IsZero(api.Sub(api.Cmp(
-					frCol,
-					cropArea.topLeft.X,
-				), 1)),
-			),
-			api.Or(
-				api.IsZero(api.Cmp(frRow, cropArea.topLeft.Y)),
-				api.IsZero(api.Sub(api.Cmp(frRow, cropArea.topLeft.Y), 1)),
-			),
-		),
-		api.And(
-			api.Or(
-				api.IsZero(api.Cmp(
-					frCol,
-					cropArea.bottomRight.X,
-				)),
-				api.IsZero(api.Add(api.Cmp(frCol, cropArea.bottomRight.X), 1)),
-			),
-			api.Or(
-				api.IsZero(api.Cmp(frRow, cropArea.bottomRight.Y)),
-				api.IsZero(api.Add(api.Cmp(frRow, cropArea.bottomRight.Y), 1)),
-			),
-		),
-	)
-
-	// true if currentIdx is within crop area
-	// withinCropArea := api.And(withinTopLeft, withinBottomRight)
-
-	return withinCropArea
+	// Check that 0 <= X0 <= X1 < width, the width is bound to the input digest
+	comparator.AssertIsLessEq(0, circuit.Params.X0)
+	comparator.AssertIsLessEq(circuit.Params.X0, circuit.Params.X1)
+	comparator.AssertIsLess(circuit.Params.X1, circuit.FrImage.Width)
+
+	// Check that 0 <= Y0 <= Y1 < height
+	comparator.AssertIsLessEq(0, circuit.Params.Y0)
+	comparator.AssertIsLessEq(circuit.Params.Y0, circuit.Params.Y1)
+	comparator.AssertIsLess(circuit.Params.Y1, circuit.FrImage.Height)
 }
 
 // type ModuloFieldCircuit[T emulated.FieldParams] struct {
@@ -199,7 +88,10 @@ func smallModHint(mod *big.Int, inputs []*big.Int, outputs []*big.Int) error {
 		return errors.New("expected 2 inputs")
 	}
 
-	// Compute the quotient and remainder, NOTE: r (aka N) cannot be 0.
+	// Compute the quotient and remainder, r cannot be 0.
+	if inputs[1].Sign() == 0 {
+		return errors.New("modulus is zero")
+	}
 	outputs[1].QuoRem(inputs[0], inputs[1], outputs[0])
 	//fmt.Println(inputs[0], inputs[1], outputs[0], outputs[1])
 	return nil
@@ -222,6 +114,30 @@ func SmallMod(api frontend.API, a, r frontend.Variable) (quo, rem frontend.Varia
 	api.AssertIsLessOrEqual(rem, bound)
 	api.AssertIsLessOrEqual(quo, bound)
 
+	// The remainder is smaller than the modulus
+	api.AssertIsLessOrEqual(api.Add(rem, 1), r)
+
+	api.AssertIsEqual(a, api.Add(api.Mul(quo, r), rem))
+	return
+}
+
+// Like SmallMod, for a dividend known to be in [0, 2^quoBits * r) and a modulus r in [1, maxMod].
+// The bounds are checked with bounded comparisons instead of comparisons over the whole field,
+// which makes it cheap enough to be used for every channel of every pixel.
+func BoundedMod(api frontend.API, a, r frontend.Variable, quoBits int, maxMod int) (quo, rem frontend.Variable) {
+	res, err := api.Compiler().NewHint(smallModHint, 2, a, r)
+	if err != nil {
+		panic(err)
+	}
+	rem = res[0]
+	quo = res[1]
+
+	// 0 <= rem < r and 0 <= quo < 2^quoBits, so quo*r + rem cannot overflow
+	comparator := cmp.NewBoundedComparator(api, big.NewInt(int64(maxMod)), false)
+	comparator.AssertIsLessEq(0, rem)
+	comparator.AssertIsLess(rem, r)
+	api.ToBinary(quo, quoBits)
+
 	api.AssertIsEqual(a, api.Add(api.Mul(quo, r), rem))
 	return
 }
@@ -239,75 +155,23 @@ func SmallMod(api frontend.API, a, r frontend.Variable) (quo, rem frontend.Varia
 // 	return nil
 // }
 
-func (circuit *CropCircuit) Transform(api frontend.API) image.FrImage {
-	// Initialize zero as a variable
-	zero := frontend.Variable(0)
+func (circuit *CropCircuit) Transform(api frontend.API) (image.FrImage, error) {
+	// The cropped image's dimensions are the ones of the crop area
+	newImage := image.NewFrImage(len(circuit.FrImage.Pixels))
+	newImage.Width = api.Add(api.Sub(circuit.Params.X1, circuit.Params.X0), 1)
+	newImage.Height = api.Add(api.Sub(circuit.Params.Y1, circuit.Params.Y0), 1)
 
-	// Initialize FrImage for returning
-	newImage := image.FrImage{}
-
-	// Initialize the cropArea using the params as fields
-	cropArea := Fr_SquareArea{
-		topLeft:     Fr_Location{X: circuit.Params.X0, Y: circuit.Params.Y0},
-		bottomRight: Fr_Location{X: circuit.Params.X1, Y: circuit.Params.Y1},
-	}
-
-	// Initialize the lookup table
-	img := logderivlookup.New(api)
-	for row := range image.N {
-		for col := range image.N {
-			flatIdx := row*image.N + col
-			img.Insert(circuit.FrImage.Pixels[flatIdx])
-		}
+	// The rest of the metadata is carried from the signed image, with the crop in its history
+	metadata, err := TransformMetadata(api, circuit.FrImage.Metadata, "crop")
+	if err != nil {
+		return image.FrImage{}, err
 	}
+	newImage.Metadata = metadata
 
-	// newImg := logderivlookup.New(api)
-	solver.RegisterHint(smallModHint)
-
-	for row := 0; row < image.N; row++ {
-		for col := 0; col < image.N; col++ {
-
-			// Calculate the current index
-			currentIdx := row*image.N + col
-
-			// Calculate the target Index when given params and currentIdx
-			// Given:
-			//        leftCornerIdx := (currentRow - Y0) * N + currentCol - X0
-			// As we traverse the image, we also encounter the left corner indeces and we can derive
-			// a "target index" from a left corner index by assuming that
-			// `leftCornerIdx == currentIdx` and we are calculating for a target index:
-			//			 currentIdx = (targetRow - Y0) * N + targetCol - X0
-			//			 currentIdx = N*targetRow - N*Y0 + targetCol - X0
-			//			 currentIdx + N*Y0 + X0 = N*targetRow + targetCol
-			//			 currentIdx + N*Y0 + X0 = targetIdx
-			targetIdx := api.Add(api.Add(api.Mul(image.N, circuit.Params.Y0), circuit.Params.X0), currentIdx)
-
-			// targetRow = targetIdx / N
-			// targetCol = targetIdx % N
-			// Use Div and Mul operations to compute a % r = b:
-			// 		1. Compute q = a / r, where q is the quotient (using integer division).
-			// 		2. Compute the remainder using remainder = a - (q * r).
-			// 		3. Assert that a == (q * r) + remainder
-			targetRow, targetCol := SmallMod(api, targetIdx, circuit.Params.N)
-
-			// true if targetIdx is within crop area
-			withinCropArea := WithinCropArea(api, targetRow, targetCol, cropArea)
-
-			targetPixel := img.Lookup(targetIdx)[0]
-			// api.Println(targetPixel, withinCropArea)
-
-			// If this location is within the crop area:
-			//			select pixel at targetIdx
-			// Else:
-			//			select black pixel
-			newPixel := api.Select(withinCropArea, targetPixel, zero)
-
-			// api.Println(newPixel)
-
-			// Add the newPixel to the newImage
-			newImage.Pixels[currentIdx] = newPixel
-		}
-	}
+	// The pixel at (row, col) of the cropped image is the pixel at (Y0 + row, X0 + col) of the image
+	newImage.Pixels = GatherPixels(api, circuit.FrImage, newImage.Width, newImage.Height, func(row, col frontend.Variable) (frontend.Variable, frontend.Variable) {
+		return api.Add(circuit.Params.Y0, row), api.Add(circuit.Params.X0, col)
+	})
 
-	return newImage
+	return newImage, nil
 }
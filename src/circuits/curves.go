@@ -0,0 +1,94 @@
+package circuits
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	eddsabls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/twistededwards/eddsa"
+	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+)
+
+// Transformation proofs can be made over BN254, or over BLS12-377 so that
+// they can be verified recursively by a BW6-761 RecursiveCircuit.
+var supportedCurves = []ecc.ID{ecc.BN254, ecc.BLS12_377}
+
+// Return the curve whose scalar field is the given field.
+func CurveOf(field *big.Int) (ecc.ID, error) {
+	for _, curve := range supportedCurves {
+		if curve.ScalarField().Cmp(field) == 0 {
+			return curve, nil
+		}
+	}
+
+	return ecc.UNKNOWN, fmt.Errorf("UNSUPPORTED FIELD %s", field)
+}
+
+// Return the twisted edwards curve defined over the scalar field of the given curve,
+// which is the curve EdDSA keys must be generated on to be verified in its circuits.
+func EdwardsID(curve ecc.ID) (tedwards.ID, error) {
+	switch curve {
+	case ecc.BN254:
+		return tedwards.BN254, nil
+	case ecc.BLS12_377:
+		return tedwards.BLS12_377, nil
+	default:
+		return tedwards.UNKNOWN, fmt.Errorf("NO TWISTED EDWARDS CURVE FOR %s", curve)
+	}
+}
+
+// Decode an EdDSA public key generated by NewSecretKey for the given curve.
+func NewPublicKey(curve ecc.ID, buf []byte) (signature.PublicKey, error) {
+	var publicKey signature.PublicKey
+	switch curve {
+	case ecc.BN254:
+		publicKey = new(eddsabn254.PublicKey)
+	case ecc.BLS12_377:
+		publicKey = new(eddsabls12377.PublicKey)
+	default:
+		return nil, fmt.Errorf("NO TWISTED EDWARDS CURVE FOR %s", curve)
+	}
+
+	n, err := publicKey.SetBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(buf) {
+		return nil, fmt.Errorf("INVALID %s PUBLIC KEY", curve)
+	}
+
+	return publicKey, nil
+}
+
+// Return the curve of the camera keys carried by proofs over the given curve.
+// A BW6-761 history proof carries the key of its BLS12-377 steps.
+func SigningCurve(curve ecc.ID) ecc.ID {
+	if curve == ecc.BW6_761 {
+		return ecc.BLS12_377
+	}
+
+	return curve
+}
+
+// Return the Groth16 prover options for proofs over the given curve.
+// BLS12-377 proofs hash their commitments in a way a BW6-761 circuit can verify.
+func ProverOptions(curve ecc.ID) []backend.ProverOption {
+	if curve == ecc.BLS12_377 {
+		return []backend.ProverOption{stdgroth16.GetNativeProverOptions(ecc.BW6_761.ScalarField(), ecc.BLS12_377.ScalarField())}
+	}
+
+	return nil
+}
+
+// Return the Groth16 verifier options matching ProverOptions.
+func VerifierOptions(curve ecc.ID) []backend.VerifierOption {
+	if curve == ecc.BLS12_377 {
+		return []backend.VerifierOption{stdgroth16.GetNativeVerifierOptions(ecc.BW6_761.ScalarField(), ecc.BLS12_377.ScalarField())}
+	}
+
+	return nil
+}
@@ -0,0 +1,367 @@
+package circuits
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"slices"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+// The leaves of a metadata commitment are the metadata fields, in the order of the
+// image.Field constants, followed by the digest of the picture.
+const (
+	DigestLeaf         = image.NbMetadataFields
+	NbDisclosureLeaves = image.NbMetadataFields + 1
+)
+
+// The camera's signed commitment to the metadata of a picture. Each leaf hides a field
+// behind a random salt, so whoever holds the commitment can disclose any subset of the
+// fields while the others stay hidden, even the ones with few possible values.
+type MetadataCommitment struct {
+	Curve     ecc.ID
+	PublicKey signature.PublicKey
+	Signature []byte // The camera's signature over the root
+	Root      []byte // Root of the Merkle tree of the salted leaves
+	Metadata  image.Metadata
+	Digest    []byte   // Digest of the picture
+	Salts     [][]byte // One random field element per leaf, to keep secret
+}
+
+// The fields of a picture's metadata chosen by the owner of a commitment, with
+// the proof that they are the ones the camera committed to.
+type Disclosure struct {
+	Curve     ecc.ID
+	PublicKey signature.PublicKey
+	Signature []byte
+	Root      []byte
+	Fields    []int          // The disclosed leaves, in increasing order
+	Metadata  image.Metadata // Only the disclosed fields are set
+	Digest    []byte         // Digest of the picture, when DigestLeaf is disclosed
+	Salts     [][]byte       // The salts of the disclosed leaves
+	Hashes    [][]byte       // Roots of the subtrees without disclosed leaves, from left to right
+}
+
+// Commit to the metadata of a picture and sign the root of the commitment with the camera's key.
+func CommitMetadata(img image.Image, secretKey signature.Signer, curve ecc.ID) (MetadataCommitment, error) {
+	digest, err := img.Digest(curve)
+	if err != nil {
+		return MetadataCommitment{}, err
+	}
+
+	commitment := MetadataCommitment{Curve: curve, PublicKey: secretKey.Public(), Metadata: img.Metadata.Clone(), Digest: digest}
+	for range NbDisclosureLeaves {
+		salt, err := rand.Int(rand.Reader, curve.ScalarField())
+		if err != nil {
+			return MetadataCommitment{}, err
+		}
+		commitment.Salts = append(commitment.Salts, salt.FillBytes(make([]byte, len(digest))))
+	}
+
+	leaves, err := commitmentLeaves(curve, allLeaves(), commitment.Metadata, commitment.Digest, commitment.Salts)
+	if err != nil {
+		return MetadataCommitment{}, err
+	}
+
+	if commitment.Root, err = merkleRoot(curve, leaves); err != nil {
+		return MetadataCommitment{}, err
+	}
+	if commitment.Signature, err = image.SignDigest(commitment.Root, secretKey, curve); err != nil {
+		return MetadataCommitment{}, errors.New("COULD NOT SIGN THE METADATA COMMITMENT")
+	}
+
+	return commitment, nil
+}
+
+// Disclose the given leaves of the commitment, every other one stays hidden.
+func (commitment MetadataCommitment) Disclose(fields ...int) (Disclosure, error) {
+	fields = slices.Clone(fields)
+	slices.Sort(fields)
+	fields = slices.Compact(fields)
+	if len(fields) == 0 || fields[0] < 0 || fields[len(fields)-1] >= NbDisclosureLeaves {
+		return Disclosure{}, errors.New("INVALID DISCLOSED FIELDS")
+	}
+
+	leaves, err := commitmentLeaves(commitment.Curve, allLeaves(), commitment.Metadata, commitment.Digest, commitment.Salts)
+	if err != nil {
+		return Disclosure{}, err
+	}
+
+	disclosure := Disclosure{
+		Curve:     commitment.Curve,
+		PublicKey: commitment.PublicKey,
+		Signature: commitment.Signature,
+		Root:      commitment.Root,
+		Fields:    fields,
+		Metadata:  discloseMetadata(commitment.Metadata, fields),
+	}
+	for _, field := range fields {
+		if field == DigestLeaf {
+			disclosure.Digest = commitment.Digest
+		}
+		disclosure.Salts = append(disclosure.Salts, commitment.Salts[field])
+	}
+
+	disclosure.Hashes, err = hiddenSubtrees(commitment.Curve, leaves, 0, isDisclosed(fields))
+	if err != nil {
+		return Disclosure{}, err
+	}
+
+	return disclosure, nil
+}
+
+// Verify that the disclosed fields are the ones a trusted camera committed to.
+func VerifyDisclosure(disclosure Disclosure, trustedKeys []signature.PublicKey) (bool, error) {
+	if !isTrusted(disclosure.PublicKey, trustedKeys) {
+		return false, errors.New("INVALID DISCLOSURE: THE CAMERA KEY IS NOT TRUSTED")
+	}
+
+	hFunc, err := image.NewMiMC(disclosure.Curve)
+	if err != nil {
+		return false, err
+	}
+	valid, err := disclosure.PublicKey.Verify(disclosure.Signature, disclosure.Root, hFunc)
+	if err != nil || !valid {
+		return false, errors.New("INVALID DISCLOSURE: THE CAMERA DID NOT SIGN THE COMMITMENT")
+	}
+
+	if !slices.IsSorted(disclosure.Fields) || len(slices.Compact(slices.Clone(disclosure.Fields))) != len(disclosure.Fields) ||
+		len(disclosure.Fields) == 0 || disclosure.Fields[0] < 0 || disclosure.Fields[len(disclosure.Fields)-1] >= NbDisclosureLeaves {
+		return false, errors.New("INVALID DISCLOSURE: INVALID DISCLOSED FIELDS")
+	}
+
+	// Rebuild the disclosed leaves from the disclosed values, then the root from the hidden subtrees
+	salts := make([][]byte, NbDisclosureLeaves)
+	if len(disclosure.Salts) != len(disclosure.Fields) {
+		return false, errors.New("INVALID DISCLOSURE: THERE IS ONE SALT PER DISCLOSED FIELD")
+	}
+	for i, field := range disclosure.Fields {
+		salts[field] = disclosure.Salts[i]
+	}
+
+	leaves, err := commitmentLeaves(disclosure.Curve, disclosure.Fields, disclosure.Metadata, disclosure.Digest, salts)
+	if err != nil {
+		return false, err
+	}
+
+	hashes := disclosure.Hashes
+	root, err := rebuildRoot(disclosure.Curve, leaves, 0, NbDisclosureLeaves, isDisclosed(disclosure.Fields), &hashes)
+	if err != nil {
+		return false, fmt.Errorf("INVALID DISCLOSURE: %w", err)
+	}
+	if len(hashes) != 0 || !bytes.Equal(root, disclosure.Root) {
+		return false, errors.New("INVALID DISCLOSURE: THE FIELDS ARE NOT THE COMMITTED ONES")
+	}
+
+	return true, nil
+}
+
+// Return the leaves of the given fields, the others are nil. Each leaf is the MiMC hash
+// of its index, its salt and the field element of the field, or the digest for DigestLeaf.
+func commitmentLeaves(curve ecc.ID, fields []int, metadata image.Metadata, digest []byte, salts [][]byte) ([][]byte, error) {
+	if len(salts) != NbDisclosureLeaves {
+		return nil, errors.New("THERE IS ONE SALT PER LEAF")
+	}
+
+	leaves := make([][]byte, NbDisclosureLeaves)
+	for _, field := range fields {
+		var value *big.Int
+		if field == DigestLeaf {
+			value = new(big.Int).SetBytes(digest)
+		} else {
+			var err error
+			if value, err = metadata.FieldElement(curve, field); err != nil {
+				return nil, err
+			}
+		}
+
+		hFunc, err := image.NewMiMC(curve)
+		if err != nil {
+			return nil, err
+		}
+
+		elem := make([]byte, hFunc.BlockSize())
+		for _, element := range []*big.Int{big.NewInt(int64(field)), new(big.Int).SetBytes(salts[field]), value} {
+			if element.BitLen() > 8*len(elem) {
+				return nil, fmt.Errorf("INVALID VALUE OF LEAF %d", field)
+			}
+			if _, err := hFunc.Write(element.FillBytes(elem)); err != nil {
+				return nil, err
+			}
+		}
+		leaves[field] = hFunc.Sum(nil)
+	}
+
+	return leaves, nil
+}
+
+// Return the root of the Merkle tree of the leaves. The tree of n > 1 leaves has the tree of
+// the first k leaves on its left, k being the largest power of 2 smaller than n, and the tree
+// of the other leaves on its right. Each node is the MiMC hash of its two children.
+func merkleRoot(curve ecc.ID, leaves [][]byte) ([]byte, error) {
+	if len(leaves) == 1 {
+		return leaves[0], nil
+	}
+
+	k := splitPoint(len(leaves))
+	left, err := merkleRoot(curve, leaves[:k])
+	if err != nil {
+		return nil, err
+	}
+	right, err := merkleRoot(curve, leaves[k:])
+	if err != nil {
+		return nil, err
+	}
+
+	return hashNodes(curve, left, right)
+}
+
+// Return the roots of the subtrees of leaves without disclosed leaves, from left to right.
+// offset is the index of the first of the leaves.
+func hiddenSubtrees(curve ecc.ID, leaves [][]byte, offset int, disclosed func(int) bool) ([][]byte, error) {
+	if !slices.ContainsFunc(indices(offset, len(leaves)), disclosed) {
+		root, err := merkleRoot(curve, leaves)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{root}, nil
+	}
+	if len(leaves) == 1 {
+		return nil, nil
+	}
+
+	k := splitPoint(len(leaves))
+	left, err := hiddenSubtrees(curve, leaves[:k], offset, disclosed)
+	if err != nil {
+		return nil, err
+	}
+	right, err := hiddenSubtrees(curve, leaves[k:], offset+k, disclosed)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(left, right...), nil
+}
+
+// Return the root of the subtree of the leaves from start to end, taking the roots of
+// the subtrees without disclosed leaves from the start of hashes.
+func rebuildRoot(curve ecc.ID, leaves [][]byte, start int, end int, disclosed func(int) bool, hashes *[][]byte) ([]byte, error) {
+	if !slices.ContainsFunc(indices(start, end-start), disclosed) {
+		if len(*hashes) == 0 {
+			return nil, errors.New("MISSING HIDDEN SUBTREE")
+		}
+		root := (*hashes)[0]
+		*hashes = (*hashes)[1:]
+		return root, nil
+	}
+	if end-start == 1 {
+		return leaves[start], nil
+	}
+
+	k := splitPoint(end - start)
+	left, err := rebuildRoot(curve, leaves, start, start+k, disclosed, hashes)
+	if err != nil {
+		return nil, err
+	}
+	right, err := rebuildRoot(curve, leaves, start+k, end, disclosed, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return hashNodes(curve, left, right)
+}
+
+// Return the metadata with only the fields of the disclosed leaves set.
+func discloseMetadata(metadata image.Metadata, fields []int) image.Metadata {
+	var disclosed image.Metadata
+	for _, field := range fields {
+		switch field {
+		case image.FieldAuthor:
+			disclosed.Author = metadata.Author
+		case image.FieldDeviceID:
+			disclosed.DeviceID = metadata.DeviceID
+		case image.FieldCaptureTime:
+			disclosed.CaptureTime = metadata.CaptureTime
+		case image.FieldWidth:
+			disclosed.Width = metadata.Width
+		case image.FieldHeight:
+			disclosed.Height = metadata.Height
+		case image.FieldLatitude, image.FieldLongitude, image.FieldHasGPS:
+			if metadata.GPS == nil {
+				continue
+			}
+			if disclosed.GPS == nil {
+				disclosed.GPS = &image.GPS{}
+			}
+			if field == image.FieldLatitude {
+				disclosed.GPS.Latitude = metadata.GPS.Latitude
+			} else if field == image.FieldLongitude {
+				disclosed.GPS.Longitude = metadata.GPS.Longitude
+			}
+		case image.FieldHasExposure, image.FieldExposureTime, image.FieldISO, image.FieldAperture:
+			if metadata.Exposure == nil {
+				continue
+			}
+			if disclosed.Exposure == nil {
+				disclosed.Exposure = &image.Exposure{}
+			}
+			switch field {
+			case image.FieldExposureTime:
+				disclosed.Exposure.Time = metadata.Exposure.Time
+			case image.FieldISO:
+				disclosed.Exposure.ISO = metadata.Exposure.ISO
+			case image.FieldAperture:
+				disclosed.Exposure.Aperture = metadata.Exposure.Aperture
+			}
+		case image.FieldHistory:
+			disclosed.History = slices.Clone(metadata.History)
+		}
+	}
+
+	return disclosed
+}
+
+func hashNodes(curve ecc.ID, left []byte, right []byte) ([]byte, error) {
+	hFunc, err := image.NewMiMC(curve)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := hFunc.Write(left); err != nil {
+		return nil, err
+	}
+	if _, err := hFunc.Write(right); err != nil {
+		return nil, err
+	}
+
+	return hFunc.Sum(nil), nil
+}
+
+// Return the largest power of 2 smaller than n > 1.
+func splitPoint(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+func allLeaves() []int {
+	return indices(0, NbDisclosureLeaves)
+}
+
+func indices(start int, n int) []int {
+	all := make([]int, n)
+	for i := range all {
+		all[i] = start + i
+	}
+	return all
+}
+
+func isDisclosed(fields []int) func(int) bool {
+	return func(leaf int) bool {
+		_, found := slices.BinarySearch(fields, leaf)
+		return found
+	}
+}
@@ -0,0 +1,72 @@
+package circuits
+
+import (
+	"testing"
+	"time"
+
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/test"
+)
+
+func TestDisclosure(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	sk, err := NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+	trusted := []signature.PublicKey{sk.Public()}
+
+	img, err := image.NewImage("random")
+	assert.NoError(err)
+	img.Metadata.CaptureTime = time.Date(2024, 5, 17, 8, 30, 0, 0, time.UTC)
+	img.Metadata.DeviceID = "camera 42"
+	img.Metadata.GPS = &image.GPS{Latitude: 48858370, Longitude: 2294481}
+
+	commitment, err := CommitMetadata(img, sk, ecc.BN254)
+	assert.NoError(err)
+
+	// Any subset of the leaves can be disclosed
+	for _, fields := range [][]int{
+		{image.FieldCaptureTime},
+		{image.FieldAuthor, image.FieldCaptureTime, DigestLeaf},
+		{image.FieldLatitude},
+		{image.FieldHistory, image.FieldWidth, image.FieldHeight},
+		allLeaves(),
+	} {
+		disclosure, err := commitment.Disclose(fields...)
+		assert.NoError(err)
+		valid, err := VerifyDisclosure(disclosure, trusted)
+		assert.NoError(err)
+		assert.True(valid)
+	}
+
+	disclosure, err := commitment.Disclose(image.FieldCaptureTime)
+	assert.NoError(err)
+	assert.Nil(disclosure.Metadata.GPS)
+	assert.Equal("", disclosure.Metadata.DeviceID)
+	assert.Equal(img.Metadata.CaptureTime, disclosure.Metadata.CaptureTime)
+
+	// A forged capture time is not the committed one
+	forged := disclosure
+	forged.Metadata.CaptureTime = disclosure.Metadata.CaptureTime.AddDate(0, 0, -1)
+	valid, err := VerifyDisclosure(forged, trusted)
+	assert.Error(err)
+	assert.False(valid)
+
+	// Nor is the capture time presented as another field
+	forged = disclosure
+	forged.Fields = []int{image.FieldAuthor}
+	forged.Metadata.Author = "Mallory"
+	valid, err = VerifyDisclosure(forged, trusted)
+	assert.Error(err)
+	assert.False(valid)
+
+	// And the commitment of an untrusted camera is rejected
+	other, err := NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+	valid, err = VerifyDisclosure(disclosure, []signature.PublicKey{other.Public()})
+	assert.Error(err)
+	assert.False(valid)
+}
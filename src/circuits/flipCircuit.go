@@ -0,0 +1,86 @@
+package circuits
+
+import (
+	"errors"
+	"src/image"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// This circuit proves that the output image is the input image mirrored left to right when
+// Horizontal is set, and top to bottom when Vertical is set. Each case is a circuit of its own
+// with its own transformation code, whose pixels are permuted by GatherPixels.
+type FlipCircuit struct {
+	PublicInputs
+	FrImage    image.FrImage
+	Horizontal bool `gnark:"-"` // Fixed when the circuit is compiled
+	Vertical   bool `gnark:"-"`
+}
+
+func (circuit *FlipCircuit) Define(api frontend.API) error {
+	code, err := TransformationCode(circuit.GetType())
+	if err != nil {
+		return err
+	}
+	if err := circuit.AssertSignedInput(api, code, circuit.FrImage); err != nil {
+		return err
+	}
+
+	flippedImage, err := circuit.Transform(api)
+	if err != nil {
+		return err
+	}
+
+	// Check that the flipped image is the one committed to by the public output digest
+	return circuit.AssertOutput(api, flippedImage)
+}
+
+// Return the transformation type of the circuit's flip.
+func (circuit *FlipCircuit) GetType() string {
+	return FlipType(circuit.Horizontal, circuit.Vertical)
+}
+
+// Return the transformation type of a flip, empty when it flips nothing.
+func FlipType(horizontal bool, vertical bool) string {
+	switch {
+	case horizontal && vertical:
+		return "flip-both"
+	case horizontal:
+		return "flip-horizontal"
+	case vertical:
+		return "flip-vertical"
+	default:
+		return ""
+	}
+}
+
+func (circuit *FlipCircuit) Transform(api frontend.API) (image.FrImage, error) {
+	if !circuit.Horizontal && !circuit.Vertical {
+		return image.FrImage{}, errors.New("INVALID FLIP: NOTHING TO MIRROR")
+	}
+
+	// The flipped image keeps the dimensions of the image
+	newImage := image.NewFrImage(len(circuit.FrImage.Pixels))
+	newImage.Width = circuit.FrImage.Width
+	newImage.Height = circuit.FrImage.Height
+
+	metadata, err := TransformMetadata(api, circuit.FrImage.Metadata, circuit.GetType())
+	if err != nil {
+		return image.FrImage{}, err
+	}
+	newImage.Metadata = metadata
+
+	// The pixel at (row, col) of the flipped image is the pixel at the mirrored row and column of the image
+	lastRow, lastCol := api.Sub(newImage.Height, 1), api.Sub(newImage.Width, 1)
+	newImage.Pixels = GatherPixels(api, circuit.FrImage, newImage.Width, newImage.Height, func(row, col frontend.Variable) (frontend.Variable, frontend.Variable) {
+		if circuit.Vertical {
+			row = api.Sub(lastRow, row)
+		}
+		if circuit.Horizontal {
+			col = api.Sub(lastCol, col)
+		}
+		return row, col
+	})
+
+	return newImage, nil
+}
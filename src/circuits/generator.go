@@ -1,6 +1,7 @@
 package circuits
 
 import (
+	"fmt"
 	"math/big"
 
 	"crypto/rand"
@@ -10,6 +11,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/signature"
 	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 )
@@ -20,9 +22,15 @@ type Keys struct {
 	SecKey  signature.Signer
 }
 
-func NewSecretKey() (signature.Signer, error) {
+// Generate an EdDSA secret key whose signatures can be verified in circuits over the given curve.
+func NewSecretKey(curve ecc.ID) (signature.Signer, error) {
+	edID, err := EdwardsID(curve)
+	if err != nil {
+		return nil, err
+	}
+
 	// 1. Generate a secret key using ceddsa.
-	sk, err := ceddsa.New(1, rand.Reader) // Generate a secret key for signing
+	sk, err := ceddsa.New(edID, rand.Reader) // Generate a secret key for signing
 	if err != nil {
 		return nil, err
 	}
@@ -32,44 +40,121 @@ func NewSecretKey() (signature.Signer, error) {
 	return sk, nil
 }
 
-// This function generates keys and constraint system for an different transformations
-func Generator(max_image_size int, security_parameter *big.Int) (Keys, Keys, error) {
+// The signing keys of one camera. Only the camera holds SecKey, everyone else
+// only needs PublicKey to check that a picture was taken by this camera.
+type CameraIdentity struct {
+	SecKey    signature.Signer
+	PublicKey signature.PublicKey
+}
 
-	sk, err := NewSecretKey()
+// Generate the signing keys of a new camera for circuits over the given curve.
+// No circuit is compiled, the camera proves against the shared public parameters.
+func NewCameraIdentity(curve ecc.ID) (CameraIdentity, error) {
+	sk, err := NewSecretKey(curve)
 	if err != nil {
-		return Keys{}, Keys{}, err
+		return CameraIdentity{}, err
 	}
 
-	//------------- Compiling Identity Transformation Keys ------------------------
+	return CameraIdentity{SecKey: sk, PublicKey: sk.Public()}, nil
+}
 
-	// Set the security parameter (BN254) and compile a constraint system (aka compliance_predicate)
-	compliance_predicate_id, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &IdentityCircuit{})
-	if err != nil {
-		return Keys{}, Keys{}, err
+// The compiled constraint system, proving and verifying keys of one transformation circuit.
+type TransformationKeys struct {
+	Compiled constraint.ConstraintSystem
+	ProvKey  groth16.ProvingKey
+	VeriKey  groth16.VerifyingKey
+}
+
+// Public parameters shared by every camera, editor and verifier: the keys of each
+// transformation circuit, generated once for a curve and a maximum image size.
+type PublicParams struct {
+	Curve        ecc.ID
+	MaxImageSize int // The maximum number of pixels, width*height, of the images the circuits accept
+	Keys         map[string]TransformationKeys
+}
+
+// Return the proving key of the given transformation type.
+func (params PublicParams) ProvingKey(transformationType string) (groth16.ProvingKey, error) {
+	keys, ok := params.Keys[transformationType]
+	if !ok {
+		return nil, fmt.Errorf("NO KEYS FOR TRANSFORMATION TYPE %q", transformationType)
 	}
+	return keys.ProvKey, nil
+}
+
+// Return the verifying key of the given transformation type.
+func (params PublicParams) VerifyingKey(transformationType string) (groth16.VerifyingKey, error) {
+	keys, ok := params.Keys[transformationType]
+	if !ok {
+		return nil, fmt.Errorf("NO KEYS FOR TRANSFORMATION TYPE %q", transformationType)
+	}
+	return keys.VeriKey, nil
+}
+
+// Return the verifying keys of every transformation, the keys a verifier trusts.
+func (params PublicParams) VerifyingKeys() VerifyingKeys {
+	vks := make(VerifyingKeys, len(params.Keys))
+	for transformationType, keys := range params.Keys {
+		vks[transformationType] = keys.VeriKey
+	}
+	return vks
+}
 
-	// Generate PCD Keys from the compliance_predicate
-	provingKey_id, vk_id, err := groth16.Setup(compliance_predicate_id)
+// This function compiles the circuits of the given transformation types (identity and crop
+// by default) and generates their keys. The circuits accept images of any width and height
+// with at most max_image_size pixels. The security parameter is the scalar field of the
+// curve to generate keys for. The result holds no secret and can be shared with everyone.
+func Setup(max_image_size int, security_parameter *big.Int, transformationTypes ...string) (PublicParams, error) {
+	curve, err := CurveOf(security_parameter)
 	if err != nil {
-		return Keys{}, Keys{}, err
+		return PublicParams{}, err
+	}
+
+	if len(transformationTypes) == 0 {
+		transformationTypes = []string{"identity", "crop"}
 	}
 
-	//------------- Compiling Crop Transformation Keys ------------------------
+	params := PublicParams{Curve: curve, MaxImageSize: max_image_size, Keys: map[string]TransformationKeys{}}
+	for _, transformationType := range transformationTypes {
+		circuit, err := CircuitOf(transformationType, max_image_size)
+		if err != nil {
+			return PublicParams{}, err
+		}
+
+		// Set the security parameter and compile a constraint system (aka compliance_predicate)
+		compliance_predicate, err := frontend.Compile(security_parameter, r1cs.NewBuilder, circuit)
+		if err != nil {
+			return PublicParams{}, err
+		}
+
+		// Generate PCD Keys from the compliance_predicate
+		provingKey, vk, err := groth16.Setup(compliance_predicate)
+		if err != nil {
+			return PublicParams{}, err
+		}
+
+		params.Keys[transformationType] = TransformationKeys{Compiled: compliance_predicate, ProvKey: provingKey, VeriKey: vk}
+	}
 
-	// Set the security parameter (BN254) and compile a constraint system (aka compliance_predicate)
-	compliance_predicate_crop, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &CropCircuit{})
+	return params, nil
+}
+
+// This function generates keys for the identity and crop transformations together with
+// a new camera identity. It is a shortcut for Setup followed by NewCameraIdentity.
+func Generator(max_image_size int, security_parameter *big.Int) (Keys, Keys, error) {
+	params, err := Setup(max_image_size, security_parameter, "identity", "crop")
 	if err != nil {
 		return Keys{}, Keys{}, err
 	}
 
-	// Generate PCD Keys from the compliance_predicate
-	provingKey_crop, vk_crop, err := groth16.Setup(compliance_predicate_crop)
+	identity, err := NewCameraIdentity(params.Curve)
 	if err != nil {
 		return Keys{}, Keys{}, err
 	}
 
-	idKeys := Keys{ProvKey: provingKey_id, VeriKey: VK{VeriKey: vk_id, PublicKey: sk.Public()}, SecKey: sk}
-	cropKeys := Keys{ProvKey: provingKey_crop, VeriKey: VK{VeriKey: vk_crop, PublicKey: sk.Public()}, SecKey: sk}
+	id, crop := params.Keys["identity"], params.Keys["crop"]
+	idKeys := Keys{ProvKey: id.ProvKey, VeriKey: VK{VeriKey: id.VeriKey, PublicKey: identity.PublicKey}, SecKey: identity.SecKey}
+	cropKeys := Keys{ProvKey: crop.ProvKey, VeriKey: VK{VeriKey: crop.VeriKey, PublicKey: identity.PublicKey}, SecKey: identity.SecKey}
 
-	return idKeys, cropKeys, err
+	return idKeys, cropKeys, nil
 }
@@ -0,0 +1,54 @@
+package circuits
+
+import (
+	"src/image"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// This circuit proves that the output image is the input image in shades of gray:
+// every pixel is replaced by a gray pixel of its luma, see image.Pixel.Luma.
+type GrayscaleCircuit struct {
+	PublicInputs
+	FrImage image.FrImage
+}
+
+func (circuit *GrayscaleCircuit) Define(api frontend.API) error {
+	if err := circuit.AssertSignedInput(api, GrayscaleCode, circuit.FrImage); err != nil {
+		return err
+	}
+
+	grayImage, err := circuit.Transform(api)
+	if err != nil {
+		return err
+	}
+
+	// Check that the gray image is the one committed to by the public output digest
+	return circuit.AssertOutput(api, grayImage)
+}
+
+func (circuit *GrayscaleCircuit) Transform(api frontend.API) (image.FrImage, error) {
+	// The gray image keeps the dimensions of the image
+	newImage := image.NewFrImage(len(circuit.FrImage.Pixels))
+	newImage.Width = circuit.FrImage.Width
+	newImage.Height = circuit.FrImage.Height
+
+	metadata, err := TransformMetadata(api, circuit.FrImage.Metadata, "grayscale")
+	if err != nil {
+		return image.FrImage{}, err
+	}
+	newImage.Metadata = metadata
+
+	for idx, pixel := range circuit.FrImage.Pixels {
+		r, g, b := UnpackRGB(api, pixel)
+
+		// The luma is the weighted sum divided by 256 and rounded, the sum is at most 255*256+128 < 2^16,
+		// so the luma is the top 8 of its 16 bits
+		sum := api.Add(api.Mul(r, image.LumaR), api.Mul(g, image.LumaG), api.Mul(b, image.LumaB), 128)
+		luma := api.FromBinary(api.ToBinary(sum, 16)[8:16]...)
+
+		newImage.Pixels[idx] = PackRGB(api, luma, luma, luma)
+	}
+
+	return newImage, nil
+}
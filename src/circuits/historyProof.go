@@ -0,0 +1,134 @@
+package circuits
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+)
+
+// Prove, in a single BW6-761 proof, the history of BLS12-377 transformation proofs that produced img.
+// The history must be ordered from the camera's capture to img, one proof per step of the keys.
+// The parameters of the history proof are the parameters of every step, in order.
+func ProveHistory(keys RecursiveKeys, history []Proof, img image.Image) (Proof, error) {
+	if len(history) != len(keys.History) {
+		return Proof{}, errors.New("INVALID HISTORY: NUMBER OF PROOFS DOES NOT MATCH THE KEYS")
+	}
+	for i, step := range history {
+		if step.Transformation != keys.History[i] {
+			return Proof{}, fmt.Errorf("INVALID HISTORY: STEP %d IS NOT A %s PROOF", i, keys.History[i])
+		}
+	}
+
+	assignment, err := newRecursiveAssignment(history, img)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	// Create the secret witness from the assignment
+	secret_witness, err := frontend.NewWitness(&assignment, ecc.BW6_761.ScalarField())
+	if err != nil {
+		return Proof{}, err
+	}
+
+	pcd_proof, err := groth16.Prove(keys.Compiled, keys.ProvKey, secret_witness)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	publicWitness, err := secret_witness.Public()
+	if err != nil {
+		return Proof{}, err
+	}
+
+	first := history[0]
+	parameters := []int{}
+	for _, step := range history {
+		parameters = append(parameters, step.Parameters...)
+	}
+
+	proof := Proof{
+		PCD_Proof:      pcd_proof,
+		Transformation: keys.Type(),
+		Signature:      first.Signature,
+		Origin_Digest:  first.Origin_Digest,
+		Input_Digest:   first.Origin_Digest,
+		Parameters:     parameters,
+		Public_Witness: publicWitness,
+		VK:             VK{VeriKey: keys.VeriKey, PublicKey: first.VK.PublicKey},
+	}
+
+	return proof, nil
+}
+
+// Assign the RecursiveCircuit from the proofs of the history that produced img.
+func newRecursiveAssignment(history []Proof, img image.Image) (RecursiveCircuit, error) {
+	// The history has the public inputs of a proof transforming the capture into img
+	historyWitness, err := newHistoryWitness(history[0], img)
+	if err != nil {
+		return RecursiveCircuit{}, err
+	}
+
+	assignment := RecursiveCircuit{History: historyWitness, Steps: make([]RecursiveStep, len(history))}
+	for i, step := range history {
+		assignment.Parameters = append(assignment.Parameters, historyParameters(step.Parameters)...)
+
+		assignment.Steps[i].Proof, err = stdgroth16.ValueOfProof[innerG1, innerG2](step.PCD_Proof)
+		if err != nil {
+			return RecursiveCircuit{}, err
+		}
+
+		assignment.Steps[i].Witness, err = stdgroth16.ValueOfWitness[innerScalar](step.Public_Witness)
+		if err != nil {
+			return RecursiveCircuit{}, err
+		}
+	}
+
+	return assignment, nil
+}
+
+// Verify a BW6-761 history proof against the image the verifier is looking at, with the trusted recursive verifying key vk.
+func verifyHistory(proof Proof, img image.Image, vk groth16.VerifyingKey) (bool, error) {
+	historyWitness, err := newHistoryWitness(proof, img)
+	if err != nil {
+		return false, err
+	}
+
+	// Only the history and the parameters of its steps are public, the steps are left empty
+	assignment := RecursiveCircuit{History: historyWitness, Parameters: historyParameters(proof.Parameters)}
+	publicWitness, err := frontend.NewWitness(&assignment, ecc.BW6_761.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return false, err
+	}
+
+	err = groth16.Verify(proof.PCD_Proof, vk, publicWitness)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Build the public inputs of a BLS12-377 proof that transforms the capture signed in proof into img.
+func newHistoryWitness(proof Proof, img image.Image) (stdgroth16.Witness[innerScalar], error) {
+	if !bytes.Equal(proof.Input_Digest, proof.Origin_Digest) {
+		return stdgroth16.Witness[innerScalar]{}, errors.New("INVALID HISTORY: IT DOES NOT START FROM THE SIGNED IMAGE")
+	}
+
+	outputDigest, err := img.Digest(ecc.BLS12_377)
+	if err != nil {
+		return stdgroth16.Witness[innerScalar]{}, err
+	}
+
+	historyWitness, err := NewPublicWitness(ecc.BLS12_377, "history", proof.VK.PublicKey, proof.Signature, proof.Origin_Digest, proof.Origin_Digest, outputDigest)
+	if err != nil {
+		return stdgroth16.Witness[innerScalar]{}, err
+	}
+
+	return stdgroth16.ValueOfWitness[innerScalar](historyWitness)
+}
@@ -0,0 +1,120 @@
+package circuits
+
+import (
+	"testing"
+
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+)
+
+// Circuits of the tests accept images of up to 20 pixels.
+const testMaxImageSize = 20
+
+// Return the FrImage of img for circuits of the tests.
+func testFrImage(assert *test.Assert, img image.Image, curve ecc.ID) image.FrImage {
+	frImage, err := img.ToFrImage(curve, testMaxImageSize)
+	assert.NoError(err)
+	return frImage
+}
+
+func TestIdentitySignatureBinding(t *testing.T) {
+	assert := test.NewAssert(t)
+	circuit, err := CircuitOf("identity", testMaxImageSize)
+	assert.NoError(err)
+
+	for _, curve := range supportedCurves {
+		sk, err := NewSecretKey(curve)
+		assert.NoError(err)
+
+		// A non-square image smaller than the circuit
+		img, err := image.NewImageOfSize("random", 5, 3)
+		assert.NoError(err)
+
+		digSig := img.Sign(sk, curve)
+		digest, err := img.Digest(curve)
+		assert.NoError(err)
+
+		publicInputs, err := NewPublicInputs(curve, "identity", sk.Public(), digSig, digest, digest, digest)
+		assert.NoError(err)
+
+		// The signature verifies against the digest of the signed pixels.
+		assert.NoError(test.IsSolved(circuit, &IdentityCircuit{
+			PublicInputs: publicInputs,
+			FrImage:      testFrImage(assert, img, curve),
+		}, curve.ScalarField()))
+
+		// Changing a single pixel changes the digest.
+		tampered := image.Image{Pixels: append([]image.Pixel{}, img.Pixels...), Metadata: img.Metadata}
+		tampered.Pixels[0] = image.Pixel{R: img.Pixels[0].R + 1, G: img.Pixels[0].G, B: img.Pixels[0].B}
+		assert.Error(test.IsSolved(circuit, &IdentityCircuit{
+			PublicInputs: publicInputs,
+			FrImage:      testFrImage(assert, tampered, curve),
+		}, curve.ScalarField()))
+
+		// So does changing the metadata.
+		resized := testFrImage(assert, img, curve)
+		resized.Width, resized.Height = 3, 5
+		assert.Error(test.IsSolved(circuit, &IdentityCircuit{
+			PublicInputs: publicInputs,
+			FrImage:      resized,
+		}, curve.ScalarField()))
+
+		// The proof is about an identity transformation.
+		mislabelled, err := NewPublicInputs(curve, "crop", sk.Public(), digSig, digest, digest, digest)
+		assert.NoError(err)
+		assert.Error(test.IsSolved(circuit, &IdentityCircuit{
+			PublicInputs: mislabelled,
+			FrImage:      testFrImage(assert, img, curve),
+		}, curve.ScalarField()))
+
+		// And the camera did not sign the tampered image.
+		tamperedDigest, err := tampered.Digest(curve)
+		assert.NoError(err)
+		forged, err := NewPublicInputs(curve, "identity", sk.Public(), digSig, tamperedDigest, tamperedDigest, tamperedDigest)
+		assert.NoError(err)
+		assert.Error(test.IsSolved(circuit, &IdentityCircuit{
+			PublicInputs: forged,
+			FrImage:      testFrImage(assert, tampered, curve),
+		}, curve.ScalarField()))
+	}
+}
+
+func TestImageSizeBounds(t *testing.T) {
+	assert := test.NewAssert(t)
+	circuit, err := CircuitOf("identity", testMaxImageSize)
+	assert.NoError(err)
+
+	sk, err := NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+
+	// An image of exactly the maximum size is accepted
+	img, err := image.NewImageOfSize("random", 4, 5)
+	assert.NoError(err)
+	proof, err := NewCaptureProof(img, sk, ecc.BN254)
+	assert.NoError(err)
+	publicInputs, err := NewTransformationInputs(ecc.BN254, "identity", proof, img, img)
+	assert.NoError(err)
+	assert.NoError(test.IsSolved(circuit, &IdentityCircuit{
+		PublicInputs: publicInputs,
+		FrImage:      testFrImage(assert, img, ecc.BN254),
+	}, ecc.BN254.ScalarField()))
+
+	// A larger one does not fit in the circuit
+	img, err = image.NewImageOfSize("random", 7, 3)
+	assert.NoError(err)
+	_, err = img.ToFrImage(ecc.BN254, testMaxImageSize)
+	assert.Error(err)
+}
+
+func TestCurveOf(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	curve, err := CurveOf(ecc.BLS12_377.ScalarField())
+	assert.NoError(err)
+	assert.Equal(ecc.BLS12_377, curve)
+
+	_, err = CurveOf(ecc.BW6_761.ScalarField())
+	assert.Error(err)
+}
@@ -0,0 +1,78 @@
+package circuits
+
+import (
+	"src/image"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+// ImageDigest computes the MiMC hash of an FrImage inside a circuit.
+// It hashes the metadata, width and height included, followed by the width*height packed
+// pixels, in the same order as image.Image.Digest, so both sides agree on the signed value.
+// The remaining pixels of the FrImage are not part of the digest.
+func ImageDigest(api frontend.API, img image.FrImage) (frontend.Variable, error) {
+	// The image must fit in the pixels the circuit was compiled for
+	AssertImageSize(api, img)
+	nbPixels := api.Mul(img.Width, img.Height)
+
+	// Create the MiMC hash function for Gnark circuits
+	mimc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hash the metadata, then every pixel, keeping the running hash after the last pixel of the image
+	mimc.Write(img.MetadataElements()...)
+	mimc.Sum()
+
+	digest := frontend.Variable(0)
+	for idx := range img.Pixels {
+		mimc.Write(img.Pixels[idx])
+		isLast := api.IsZero(api.Sub(nbPixels, idx+1))
+		digest = api.Add(digest, api.Mul(isLast, mimc.Sum()))
+	}
+
+	return digest, nil
+}
+
+// VerifyDigestSignature asserts that the signature was produced by the public key over the digest.
+func VerifyDigestSignature(api frontend.API, publicKey eddsa.PublicKey, signature eddsa.Signature, digest frontend.Variable) error {
+	// Create the Twisted Edwards Curve defined over the circuit's field
+	curve, err := CurveOf(api.Compiler().Field())
+	if err != nil {
+		return err
+	}
+	edID, err := EdwardsID(curve)
+	if err != nil {
+		return err
+	}
+	edCurve, err := twistededwards.NewEdCurve(api, edID)
+	if err != nil {
+		return err
+	}
+
+	// Create the MiMC hash function for Gnark circuits
+	mimc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+
+	// Verify the signature against the image digest,
+	// using the public key, twisted edwards curve and hash function
+	return eddsa.Verify(edCurve, signature, digest, publicKey, &mimc)
+}
+
+// Assert that the public digest is the digest of the image.
+func assertDigest(api frontend.API, publicDigest frontend.Variable, img image.FrImage) error {
+	digest, err := ImageDigest(api, img)
+	if err != nil {
+		return err
+	}
+
+	api.AssertIsEqual(publicDigest, digest)
+
+	return nil
+}
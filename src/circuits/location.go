@@ -0,0 +1,320 @@
+package circuits
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+// A GPS position as field elements, offset like the metadata fields so that it is not negative.
+type FrPosition struct {
+	Latitude  frontend.Variable
+	Longitude frontend.Variable
+}
+
+// This circuit proves that the signed GPS position of a picture lies in a public area,
+// without revealing the position. The camera signs the root of a metadata commitment
+// (see CommitMetadata), the circuit verifies the signature, rebuilds the root from the
+// leaves and recomputes the GPS leaves from the hidden position and salts.
+//
+// The area is a polygon of at most len(Area) vertices, unused vertices repeat the last one.
+// A position is in the area when a ray going east from it crosses the edges of the polygon
+// an odd number of times, so the polygon does not need to be convex. A position on the
+// western or southern edges of the area is in it, one on the eastern or northern edges is not.
+type LocationCircuit struct {
+	PublicKey       eddsa.PublicKey   `gnark:",public"`
+	EdDSA_Signature eddsa.Signature   `gnark:",public"` // Signature over the root
+	Root            frontend.Variable `gnark:",public"` // Root of the metadata commitment
+	Area            []FrPosition      `gnark:",public"` // Vertices of the polygon, in order
+
+	Leaves     [NbDisclosureLeaves]frontend.Variable
+	HasGPSSalt frontend.Variable // Salt of the FieldHasGPS leaf
+	Latitude   frontend.Variable
+	LatSalt    frontend.Variable
+	Longitude  frontend.Variable
+	LonSalt    frontend.Variable
+}
+
+// The compiled constraint system and keys of the location circuit for areas of at most NbVertices vertices.
+type LocationKeys struct {
+	NbVertices int
+	TransformationKeys
+}
+
+// The proof that the signed GPS position of a picture lies in Area.
+// Root and Signature are those of the picture's metadata commitment, so a Disclosure
+// of other fields of the same picture can be checked against the same signed root.
+type LocationProof struct {
+	Curve     ecc.ID
+	PublicKey signature.PublicKey
+	Signature []byte
+	Root      []byte
+	Area      []image.GPS
+	Proof     groth16.Proof
+}
+
+// Return a location circuit for areas of at most nb_vertices vertices, ready to be compiled.
+func NewLocationCircuit(nb_vertices int) *LocationCircuit {
+	return &LocationCircuit{Area: make([]FrPosition, nb_vertices)}
+}
+
+func (circuit *LocationCircuit) Define(api frontend.API) error {
+	// The camera signed the root
+	if err := VerifyDigestSignature(api, circuit.PublicKey, circuit.EdDSA_Signature, circuit.Root); err != nil {
+		return err
+	}
+
+	// The leaves make up the signed root
+	root, err := merkleRootCircuit(api, circuit.Leaves[:])
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(circuit.Root, root)
+
+	// The picture has a GPS position, and it is the hidden one
+	for _, leaf := range []struct {
+		field int
+		salt  frontend.Variable
+		value frontend.Variable
+	}{
+		{image.FieldHasGPS, circuit.HasGPSSalt, 1},
+		{image.FieldLatitude, circuit.LatSalt, circuit.Latitude},
+		{image.FieldLongitude, circuit.LonSalt, circuit.Longitude},
+	} {
+		hFunc, err := mimc.NewMiMC(api)
+		if err != nil {
+			return err
+		}
+		hFunc.Write(leaf.field, leaf.salt, leaf.value)
+		api.AssertIsEqual(circuit.Leaves[leaf.field], hFunc.Sum())
+	}
+
+	// The signed position is a valid one, so the differences of coordinates are bounded by the
+	// size of the globe and the cross products by its square, the area is checked by the verifier.
+	coordinates := cmp.NewBoundedComparator(api, big.NewInt(2*image.LongitudeOffset), false)
+	products := cmp.NewBoundedComparator(api, big.NewInt(8*image.LatitudeOffset*image.LongitudeOffset), false)
+
+	crossings := frontend.Variable(0)
+	for i, a := range circuit.Area {
+		b := circuit.Area[(i+1)%len(circuit.Area)]
+
+		// The edge goes from one side of the position's latitude to the other
+		straddles := api.Xor(coordinates.IsLess(circuit.Latitude, a.Latitude), coordinates.IsLess(circuit.Latitude, b.Latitude))
+
+		// and the position is west of the edge
+		d := api.Sub(b.Latitude, a.Latitude)
+		cross := api.Sub(
+			api.Mul(api.Sub(b.Longitude, a.Longitude), api.Sub(circuit.Latitude, a.Latitude)),
+			api.Mul(api.Sub(circuit.Longitude, a.Longitude), d),
+		)
+		cross = api.Select(coordinates.IsLess(d, 0), api.Neg(cross), cross)
+		crossings = api.Add(crossings, api.Mul(straddles, products.IsLess(0, cross)))
+	}
+
+	// An odd number of crossings
+	api.AssertIsEqual(api.ToBinary(crossings, bits.Len(uint(len(circuit.Area))))[0], 1)
+
+	return nil
+}
+
+// Return the root of the Merkle tree of the leaves, like merkleRoot.
+func merkleRootCircuit(api frontend.API, leaves []frontend.Variable) (frontend.Variable, error) {
+	if len(leaves) == 1 {
+		return leaves[0], nil
+	}
+
+	k := splitPoint(len(leaves))
+	left, err := merkleRootCircuit(api, leaves[:k])
+	if err != nil {
+		return nil, err
+	}
+	right, err := merkleRootCircuit(api, leaves[k:])
+	if err != nil {
+		return nil, err
+	}
+
+	hFunc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return nil, err
+	}
+	hFunc.Write(left, right)
+	return hFunc.Sum(), nil
+}
+
+// Compile the location circuit for areas of at most nb_vertices vertices and generate its keys.
+func SetupLocation(curve ecc.ID, nb_vertices int) (LocationKeys, error) {
+	if nb_vertices < 3 {
+		return LocationKeys{}, fmt.Errorf("INVALID NUMBER OF VERTICES %d", nb_vertices)
+	}
+
+	compiled, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, NewLocationCircuit(nb_vertices))
+	if err != nil {
+		return LocationKeys{}, err
+	}
+
+	provingKey, vk, err := groth16.Setup(compiled)
+	if err != nil {
+		return LocationKeys{}, err
+	}
+
+	return LocationKeys{NbVertices: nb_vertices, TransformationKeys: TransformationKeys{Compiled: compiled, ProvKey: provingKey, VeriKey: vk}}, nil
+}
+
+// Return the area of the box between the given latitudes and longitudes, in microdegrees.
+func BoxArea(south int64, west int64, north int64, east int64) []image.GPS {
+	return []image.GPS{
+		{Latitude: south, Longitude: west},
+		{Latitude: south, Longitude: east},
+		{Latitude: north, Longitude: east},
+		{Latitude: north, Longitude: west},
+	}
+}
+
+// Report whether the position lies in the area, by the rules of the LocationCircuit.
+func InArea(position image.GPS, area []image.GPS) bool {
+	crossings := 0
+	for i, a := range area {
+		b := area[(i+1)%len(area)]
+
+		d := b.Latitude - a.Latitude
+		cross := (b.Longitude-a.Longitude)*(position.Latitude-a.Latitude) - (position.Longitude-a.Longitude)*d
+		if d < 0 {
+			cross = -cross
+		}
+		if (position.Latitude < a.Latitude) != (position.Latitude < b.Latitude) && cross > 0 {
+			crossings++
+		}
+	}
+
+	return crossings%2 == 1
+}
+
+// Prove that the GPS position committed to lies in the area, without revealing it.
+func ProveLocation(commitment MetadataCommitment, area []image.GPS, keys LocationKeys) (LocationProof, error) {
+	if commitment.Metadata.GPS == nil {
+		return LocationProof{}, errors.New("THE PICTURE HAS NO GPS POSITION")
+	}
+	if !InArea(*commitment.Metadata.GPS, area) {
+		return LocationProof{}, errors.New("THE GPS POSITION IS NOT IN THE AREA")
+	}
+
+	assignment, err := newLocationProverAssignment(commitment, area, keys.NbVertices)
+	if err != nil {
+		return LocationProof{}, err
+	}
+
+	secret_witness, err := frontend.NewWitness(assignment, commitment.Curve.ScalarField())
+	if err != nil {
+		return LocationProof{}, err
+	}
+
+	proof, err := groth16.Prove(keys.Compiled, keys.ProvKey, secret_witness, ProverOptions(commitment.Curve)...)
+	if err != nil {
+		return LocationProof{}, err
+	}
+
+	return LocationProof{
+		Curve:     commitment.Curve,
+		PublicKey: commitment.PublicKey,
+		Signature: commitment.Signature,
+		Root:      commitment.Root,
+		Area:      area,
+		Proof:     proof,
+	}, nil
+}
+
+// Verify that a trusted camera signed a GPS position in the area of the proof.
+// The public witness is rebuilt from the proof's area, so the verifier knows which area was proven.
+func VerifyLocation(proof LocationProof, keys LocationKeys, trustedKeys []signature.PublicKey) (bool, error) {
+	if !isTrusted(proof.PublicKey, trustedKeys) {
+		return false, errors.New("INVALID LOCATION PROOF: THE CAMERA KEY IS NOT TRUSTED")
+	}
+
+	publicWitness, err := newLocationWitness(proof, keys.NbVertices)
+	if err != nil {
+		return false, err
+	}
+
+	if err := groth16.Verify(proof.Proof, keys.VeriKey, publicWitness, VerifierOptions(proof.Curve)...); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Build the public witness of a location proof for areas of at most nb_vertices vertices.
+func newLocationWitness(proof LocationProof, nb_vertices int) (witness.Witness, error) {
+	assignment, err := newLocationAssignment(proof.Curve, proof.PublicKey, proof.Signature, proof.Root, proof.Area, nb_vertices)
+	if err != nil {
+		return nil, err
+	}
+
+	return frontend.NewWitness(assignment, proof.Curve.ScalarField(), frontend.PublicOnly())
+}
+
+// Assign every input of the location circuit, the hidden position and the leaves of the commitment included.
+func newLocationProverAssignment(commitment MetadataCommitment, area []image.GPS, nb_vertices int) (*LocationCircuit, error) {
+	if commitment.Metadata.GPS == nil {
+		return nil, errors.New("THE PICTURE HAS NO GPS POSITION")
+	}
+
+	assignment, err := newLocationAssignment(commitment.Curve, commitment.PublicKey, commitment.Signature, commitment.Root, area, nb_vertices)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := commitmentLeaves(commitment.Curve, allLeaves(), commitment.Metadata, commitment.Digest, commitment.Salts)
+	if err != nil {
+		return nil, err
+	}
+	for i, leaf := range leaves {
+		assignment.Leaves[i] = leaf
+	}
+	assignment.HasGPSSalt = commitment.Salts[image.FieldHasGPS]
+	assignment.Latitude = commitment.Metadata.GPS.Latitude + image.LatitudeOffset
+	assignment.LatSalt = commitment.Salts[image.FieldLatitude]
+	assignment.Longitude = commitment.Metadata.GPS.Longitude + image.LongitudeOffset
+	assignment.LonSalt = commitment.Salts[image.FieldLongitude]
+
+	return assignment, nil
+}
+
+// Assign the public inputs of the location circuit, padding the area with its last vertex.
+func newLocationAssignment(curve ecc.ID, publicKey signature.PublicKey, digSig []byte, root []byte, area []image.GPS, nb_vertices int) (*LocationCircuit, error) {
+	if len(area) < 3 || len(area) > nb_vertices {
+		return nil, fmt.Errorf("AN AREA HAS FROM 3 TO %d VERTICES", nb_vertices)
+	}
+
+	edID, err := EdwardsID(curve)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment := NewLocationCircuit(nb_vertices)
+	assignment.PublicKey.Assign(edID, publicKey.Bytes())
+	assignment.EdDSA_Signature.Assign(edID, digSig)
+	assignment.Root = root
+
+	for i := range assignment.Area {
+		vertex := area[min(i, len(area)-1)]
+		if vertex.Latitude < -image.LatitudeOffset || vertex.Latitude > image.LatitudeOffset ||
+			vertex.Longitude < -image.LongitudeOffset || vertex.Longitude > image.LongitudeOffset {
+			return nil, fmt.Errorf("INVALID VERTEX %d OF THE AREA", i)
+		}
+		assignment.Area[i] = FrPosition{Latitude: vertex.Latitude + image.LatitudeOffset, Longitude: vertex.Longitude + image.LongitudeOffset}
+	}
+
+	return assignment, nil
+}
@@ -0,0 +1,90 @@
+package circuits
+
+import (
+	"testing"
+
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/test"
+)
+
+func TestLocationCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+	nbVertices := 6
+
+	sk, err := NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+
+	img, err := image.NewImage("random")
+	assert.NoError(err)
+	img.Metadata.GPS = &image.GPS{Latitude: 48858370, Longitude: 2294481}
+
+	commitment, err := CommitMetadata(img, sk, ecc.BN254)
+	assert.NoError(err)
+
+	// An L shaped area whose bounding box holds the position, but not the area itself
+	paris := BoxArea(48815573, 2224199, 48902145, 2469920)
+	lShape := []image.GPS{
+		{Latitude: 48815573, Longitude: 2224199},
+		{Latitude: 48815573, Longitude: 2469920},
+		{Latitude: 48840000, Longitude: 2469920},
+		{Latitude: 48840000, Longitude: 2260000},
+		{Latitude: 48902145, Longitude: 2260000},
+		{Latitude: 48902145, Longitude: 2224199},
+	}
+	assert.True(InArea(*img.Metadata.GPS, paris))
+	assert.False(InArea(*img.Metadata.GPS, lShape))
+	assert.True(InArea(image.GPS{Latitude: 48830000, Longitude: 2400000}, lShape))
+
+	// The box includes its western and southern edges only
+	assert.True(InArea(image.GPS{Latitude: 48815573, Longitude: 2224199}, paris))
+	assert.False(InArea(image.GPS{Latitude: 48902145, Longitude: 2300000}, paris))
+	assert.False(InArea(image.GPS{Latitude: 48850000, Longitude: 2469920}, paris))
+
+	circuit := NewLocationCircuit(nbVertices)
+	for _, c := range []struct {
+		area  []image.GPS
+		valid bool
+	}{
+		{paris, true},
+		{lShape, false},
+		{BoxArea(-90000000, -180000000, 90000000, 180000000), true},
+		{BoxArea(-90000000, -180000000, 0, 0), false},
+	} {
+		assignment, err := newLocationProverAssignment(commitment, c.area, nbVertices)
+		assert.NoError(err)
+		err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+		if c.valid {
+			assert.NoError(err)
+		} else {
+			assert.Error(err)
+		}
+	}
+
+	// A position that was not signed is rejected, even in the area
+	forged := commitment
+	forged.Metadata.GPS = &image.GPS{Latitude: 48858371, Longitude: 2294481}
+	assignment, err := newLocationProverAssignment(forged, paris, nbVertices)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The whole proof only reveals the area
+	keys, err := SetupLocation(ecc.BN254, nbVertices)
+	assert.NoError(err)
+	proof, err := ProveLocation(commitment, paris, keys)
+	assert.NoError(err)
+	valid, err := VerifyLocation(proof, keys, []signature.PublicKey{sk.Public()})
+	assert.NoError(err)
+	assert.True(valid)
+
+	// And is not valid for a smaller area
+	proof.Area = BoxArea(48815573, 2224199, 48850000, 2469920)
+	valid, err = VerifyLocation(proof, keys, []signature.PublicKey{sk.Public()})
+	assert.Error(err)
+	assert.False(valid)
+
+	_, err = ProveLocation(commitment, lShape, keys)
+	assert.Error(err)
+}
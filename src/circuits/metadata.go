@@ -0,0 +1,36 @@
+package circuits
+
+import (
+	"src/image"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// Return the metadata of the image output by a transformation of the given type.
+// Every signed field is carried unchanged from the input, the author and device ID
+// in particular, and the transformation is appended to the history. The circuits then
+// only set the width and height of their output, so a verifier who only sees the output
+// metadata knows it was derived from the signed metadata by these rules.
+func TransformMetadata(api frontend.API, metadata image.FrMetadata, transformationType string) (image.FrMetadata, error) {
+	curve, err := CurveOf(api.Compiler().Field())
+	if err != nil {
+		return image.FrMetadata{}, err
+	}
+
+	// The element of the transformation type is a constant of the circuit
+	element, err := image.StringElement(curve, transformationType)
+	if err != nil {
+		return image.FrMetadata{}, err
+	}
+
+	hFunc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return image.FrMetadata{}, err
+	}
+	hFunc.Write(metadata.History, element)
+
+	output := metadata
+	output.History = hFunc.Sum()
+	return output, nil
+}
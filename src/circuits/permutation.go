@@ -0,0 +1,186 @@
+package circuits
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// A Beneš network of size inputs, a power of 2, routes its inputs to any permutation of them with
+// 2*log2(size)-1 columns of size/2 switches. A switch either passes its two inputs or swaps them.
+// The wiring between the columns is fixed, only the bit of every switch is part of the witness:
+// the inputs go through a column of switches, then half of them through an upper network of size/2
+// inputs and half through a lower one, then through a last column of switches.
+
+// Return the number of switches of a Beneš network of size inputs.
+func benesSwitches(size int) int {
+	switch {
+	case size <= 1:
+		return 0
+	case size == 2:
+		return 1
+	default:
+		return size + 2*benesSwitches(size/2)
+	}
+}
+
+// Route every lane, the same number of values per lane, through the Beneš network whose switches are
+// set by bits, in the order of benesRoute. Every lane is permuted the same way.
+func benesNetwork(api frontend.API, lanes [][]frontend.Variable, bits []frontend.Variable) [][]frontend.Variable {
+	routed, _ := routeBenes(api, lanes, bits)
+	return routed
+}
+
+// Route the lanes through the network of their size and return the bits left for the next networks.
+func routeBenes(api frontend.API, lanes [][]frontend.Variable, bits []frontend.Variable) ([][]frontend.Variable, []frontend.Variable) {
+	size := len(lanes[0])
+	if size == 1 {
+		return lanes, bits
+	}
+
+	routed := newLanes(len(lanes), size)
+	if size == 2 {
+		for l, lane := range lanes {
+			routed[l][0], routed[l][1] = switchPair(api, bits[0], lane[0], lane[1])
+		}
+		return routed, bits[1:]
+	}
+
+	// The first column sends one input of every switch to each half
+	half := size / 2
+	upper, lower := newLanes(len(lanes), half), newLanes(len(lanes), half)
+	for i := 0; i < half; i++ {
+		for l, lane := range lanes {
+			upper[l][i], lower[l][i] = switchPair(api, bits[i], lane[2*i], lane[2*i+1])
+		}
+	}
+	bits = bits[half:]
+
+	upper, bits = routeBenes(api, upper, bits)
+	lower, bits = routeBenes(api, lower, bits)
+
+	// The last column merges the outputs of both halves
+	for i := 0; i < half; i++ {
+		for l := range lanes {
+			routed[l][2*i], routed[l][2*i+1] = switchPair(api, bits[i], upper[l][i], lower[l][i])
+		}
+	}
+
+	return routed, bits[half:]
+}
+
+// Return (a, b), or (b, a) when swap is 1.
+func switchPair(api frontend.API, swap frontend.Variable, a frontend.Variable, b frontend.Variable) (frontend.Variable, frontend.Variable) {
+	first := api.Select(swap, b, a)
+	return first, api.Sub(api.Add(a, b), first)
+}
+
+func newLanes(nbLanes int, size int) [][]frontend.Variable {
+	lanes := make([][]frontend.Variable, nbLanes)
+	for l := range lanes {
+		lanes[l] = make([]frontend.Variable, size)
+	}
+	return lanes
+}
+
+// benesHint sets the switches of a Beneš network whose output k is its input sources[k] for every k < n.
+// Its inputs are the size of the network, n and the sources of every output, the sources past n are ignored:
+// the inputs that are the source of no output below n go to the outputs from n on, in increasing order.
+func benesHint(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	size, n := int(inputs[0].Int64()), int(inputs[1].Int64())
+	if len(inputs) != size+2 || n < 0 || n > size {
+		return fmt.Errorf("INVALID PERMUTATION OF %d OUT OF %d INPUTS", n, size)
+	}
+
+	sources := make([]int, size)
+	used := make([]bool, size)
+	for k := 0; k < n; k++ {
+		source := inputs[2+k]
+		if !source.IsInt64() || source.Int64() < 0 || source.Int64() >= int64(size) || used[source.Int64()] {
+			return fmt.Errorf("INVALID SOURCE %s OF OUTPUT %d", source, k)
+		}
+		sources[k] = int(source.Int64())
+		used[sources[k]] = true
+	}
+
+	next := 0
+	for k := n; k < size; k++ {
+		for used[next] {
+			next++
+		}
+		sources[k] = next
+		used[next] = true
+	}
+
+	bits := benesRoute(sources)
+	if len(bits) != len(outputs) {
+		return fmt.Errorf("EXPECTED %d SWITCHES, GOT %d", len(bits), len(outputs))
+	}
+	for i, bit := range bits {
+		outputs[i].SetUint64(uint64(bit))
+	}
+
+	return nil
+}
+
+// Return the bits of the switches of a Beneš network whose output k is its input sources[k],
+// in the order routeBenes reads them: the first column, the upper network, the lower network
+// and the last column. The switches are set by the looping algorithm.
+func benesRoute(sources []int) []uint {
+	size := len(sources)
+	if size == 1 {
+		return nil
+	}
+	if size == 2 {
+		return []uint{uint(sources[0])}
+	}
+
+	half := size / 2
+	destinations := make([]int, size)
+	for k, source := range sources {
+		destinations[source] = k
+	}
+
+	// Whether every input and output goes through the lower network, -1 while unknown
+	inLower, outLower := make([]int, size), make([]int, size)
+	for k := range inLower {
+		inLower[k], outLower[k] = -1, -1
+	}
+
+	// The two inputs of a switch go to different networks, and so do the two outputs of a switch.
+	// Starting from an output of the upper network, follow the loop of constraints until it closes.
+	for start := 0; start < size; start += 2 {
+		for out := start; outLower[out] == -1; {
+			outLower[out], inLower[sources[out]] = 0, 0
+
+			sibling := sources[out] ^ 1
+			inLower[sibling], outLower[destinations[sibling]] = 1, 1
+
+			out = destinations[sibling] ^ 1
+		}
+	}
+
+	upper, lower := make([]int, half), make([]int, half)
+	for i := 0; i < half; i++ {
+		for _, out := range []int{2 * i, 2*i + 1} {
+			if outLower[out] == 0 {
+				upper[i] = sources[out] / 2
+			} else {
+				lower[i] = sources[out] / 2
+			}
+		}
+	}
+
+	bits := make([]uint, 0, benesSwitches(size))
+	for i := 0; i < half; i++ {
+		bits = append(bits, uint(inLower[2*i]))
+	}
+	bits = append(bits, benesRoute(upper)...)
+	bits = append(bits, benesRoute(lower)...)
+	for i := 0; i < half; i++ {
+		bits = append(bits, uint(outLower[2*i]))
+	}
+
+	return bits
+}
@@ -0,0 +1,61 @@
+package circuits
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark/test"
+)
+
+// Apply the switches of a Beneš network, in the order of benesRoute, to its inputs.
+func applyBenes(inputs []int, bits []uint) ([]int, []uint) {
+	size := len(inputs)
+	if size == 1 {
+		return inputs, bits
+	}
+	pair := func(swap uint, a, b int) (int, int) {
+		if swap == 1 {
+			return b, a
+		}
+		return a, b
+	}
+	routed := make([]int, size)
+	if size == 2 {
+		routed[0], routed[1] = pair(bits[0], inputs[0], inputs[1])
+		return routed, bits[1:]
+	}
+
+	half := size / 2
+	upper, lower := make([]int, half), make([]int, half)
+	for i := 0; i < half; i++ {
+		upper[i], lower[i] = pair(bits[i], inputs[2*i], inputs[2*i+1])
+	}
+	upper, bits = applyBenes(upper, bits[half:])
+	lower, bits = applyBenes(lower, bits)
+	for i := 0; i < half; i++ {
+		routed[2*i], routed[2*i+1] = pair(bits[i], upper[i], lower[i])
+	}
+	return routed, bits[half:]
+}
+
+func TestBenesRoute(t *testing.T) {
+	assert := test.NewAssert(t)
+	random := rand.New(rand.NewSource(1))
+
+	for _, size := range []int{1, 2, 4, 8, 16, 64} {
+		identity := make([]int, size)
+		for k := range identity {
+			identity[k] = k
+		}
+		for trial := 0; trial < 20; trial++ {
+			sources := random.Perm(size)
+			bits := benesRoute(sources)
+			assert.Equal(benesSwitches(size), len(bits))
+
+			// Output k of the network is its input sources[k]
+			routed, left := applyBenes(identity, bits)
+			assert.Equal(sources, routed, "size %d", size)
+			assert.Empty(left)
+		}
+	}
+}
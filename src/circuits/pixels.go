@@ -0,0 +1,121 @@
+package circuits
+
+import (
+	"math/big"
+	"src/image"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/cmp"
+)
+
+func init() {
+	// Register the hints once, so that compiled circuits can be solved without being defined again
+	solver.RegisterHint(smallModHint, benesHint)
+}
+
+// Return a comparator for values that differ by at most the number of pixels of the image,
+// such as pixel indices, rows, columns, widths and heights.
+func NewPixelComparator(api frontend.API, img image.FrImage) *cmp.BoundedComparator {
+	return cmp.NewBoundedComparator(api, big.NewInt(int64(len(img.Pixels))), false)
+}
+
+// AssertImageSize asserts that the image has at least one pixel and fits in the pixels of the FrImage.
+// Bounding the width and height first ensures their product cannot overflow the field.
+func AssertImageSize(api frontend.API, img image.FrImage) {
+	comparator := NewPixelComparator(api, img)
+	maxPixels := len(img.Pixels)
+
+	comparator.AssertIsLessEq(1, img.Width)
+	comparator.AssertIsLessEq(1, img.Height)
+	comparator.AssertIsLessEq(img.Width, maxPixels)
+	comparator.AssertIsLessEq(img.Height, maxPixels)
+	comparator.AssertIsLessEq(api.Mul(img.Width, img.Height), maxPixels)
+}
+
+// Return the row and column of the pixel at index idx of an image of the given width.
+// The width must be at least 1 and idx must be a pixel index of the comparator's image.
+func PixelPosition(api frontend.API, comparator *cmp.BoundedComparator, idx int, width frontend.Variable) (row, col frontend.Variable) {
+	res, err := api.Compiler().NewHint(smallModHint, 2, idx, width)
+	if err != nil {
+		panic(err)
+	}
+	col = res[0]
+	row = res[1]
+
+	// 0 <= col < width and 0 <= row <= idx, so idx == row*width + col cannot overflow
+	comparator.AssertIsLessEq(0, col)
+	comparator.AssertIsLess(col, width)
+	comparator.AssertIsLessEq(0, row)
+	comparator.AssertIsLessEq(row, idx)
+	api.AssertIsEqual(idx, api.Add(api.Mul(row, width), col))
+
+	return row, col
+}
+
+// Return the red, green and blue channels of a pixel packed by image.Pixel.PackRGB.
+// The pixel must be a packed pixel, a value of at most 24 bits.
+func UnpackRGB(api frontend.API, pixel frontend.Variable) (r, g, b frontend.Variable) {
+	bits := api.ToBinary(pixel, 24)
+	return api.FromBinary(bits[16:24]...), api.FromBinary(bits[8:16]...), api.FromBinary(bits[0:8]...)
+}
+
+// Return the pixel packed from its red, green and blue channels, like image.Pixel.PackRGB.
+func PackRGB(api frontend.API, r, g, b frontend.Variable) frontend.Variable {
+	return api.Add(api.Mul(r, 1<<16), api.Mul(g, 1<<8), b)
+}
+
+// Return the pixels of an image of the given width and height, whose pixel at (row, col) is the pixel
+// at source(row, col) of img. The pixels past width*height are black. The same gadget proves crops,
+// rotations and flips, which only differ by their source positions. source must return a position
+// within img for every (row, col) of the new image, and no position twice.
+//
+// The pixels go through a Beneš network, whose wiring is fixed and whose switches are set by a hint,
+// along with the index every pixel comes from. The circuit then checks that the pixel routed to every
+// position of the new image comes from the index of its source, so no pixel is looked up.
+func GatherPixels(api frontend.API, img image.FrImage, width, height frontend.Variable, source func(row, col frontend.Variable) (srcRow, srcCol frontend.Variable)) []frontend.Variable {
+	comparator := NewPixelComparator(api, img)
+
+	// The network permutes a power of 2 of pixels, the padding is black
+	size := 1
+	for size < len(img.Pixels) {
+		size *= 2
+	}
+	indices, pixels, sources := make([]frontend.Variable, size), make([]frontend.Variable, size), make([]frontend.Variable, size)
+	for idx := range indices {
+		indices[idx], pixels[idx], sources[idx] = idx, 0, 0
+	}
+	copy(pixels, img.Pixels)
+
+	withinImage := make([]frontend.Variable, len(img.Pixels))
+	for idx := range img.Pixels {
+		row, col := PixelPosition(api, comparator, idx, width)
+		srcRow, srcCol := source(row, col)
+		sources[idx] = api.Add(api.Mul(srcRow, img.Width), srcCol)
+
+		// true if idx is a pixel of the new image, the remaining pixels are black
+		withinImage[idx] = comparator.IsLess(row, height)
+	}
+
+	// The switches route the source of every pixel of the new image to its position
+	routed := [][]frontend.Variable{indices, pixels}
+	if nbSwitches := benesSwitches(size); nbSwitches > 0 {
+		bits, err := api.Compiler().NewHint(benesHint, nbSwitches, append([]frontend.Variable{size, api.Mul(width, height)}, sources...)...)
+		if err != nil {
+			panic(err)
+		}
+		for _, bit := range bits {
+			api.AssertIsBoolean(bit)
+		}
+		routed = benesNetwork(api, routed, bits)
+	}
+
+	newPixels := make([]frontend.Variable, len(img.Pixels))
+	for idx := range newPixels {
+		// Every switch permutes its inputs, so the indices are routed with their pixels
+		api.AssertIsEqual(api.Mul(withinImage[idx], api.Sub(routed[0][idx], sources[idx])), 0)
+		newPixels[idx] = api.Mul(withinImage[idx], routed[1][idx])
+	}
+
+	return newPixels
+}
@@ -1,6 +1,10 @@
 package circuits
 
 import (
+	"errors"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/signature"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/backend/witness"
@@ -8,8 +12,12 @@ import (
 
 type Proof struct {
 	PCD_Proof      groth16.Proof
-	Signature      []byte
-	Public_Witness witness.Witness
+	Transformation string          // The transformation type, as returned by GetType
+	Signature      []byte          // The camera's signature over the origin digest
+	Origin_Digest  []byte          // Digest of the image signed by the camera
+	Input_Digest   []byte          // Digest of the image that was transformed
+	Parameters     []int           // The public parameters of the transformation, such as the brightness delta
+	Public_Witness witness.Witness // The prover's public witness, Verifier rebuilds its own from the image.
 	VK             VK
 }
 
@@ -17,3 +25,25 @@ type VK struct {
 	VeriKey   groth16.VerifyingKey
 	PublicKey signature.PublicKey
 }
+
+// Return the proof a camera attaches to a picture it just captured: its public key
+// and its signature over the picture's digest, before any transformation was proven.
+// Transformation provers carry this signature forward instead of signing themselves.
+func NewCaptureProof(img image.Image, secretKey signature.Signer, curve ecc.ID) (Proof, error) {
+	digest, err := img.Digest(curve)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	digSig := img.Sign(secretKey, curve)
+	if len(digSig) == 0 {
+		return Proof{}, errors.New("COULD NOT SIGN THE CAPTURED IMAGE")
+	}
+
+	return Proof{
+		Signature:     digSig,
+		Origin_Digest: digest,
+		Input_Digest:  digest,
+		VK:            VK{PublicKey: secretKey.Public()},
+	}, nil
+}
@@ -0,0 +1,106 @@
+package circuits
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+// The proofs of every transformation applied to an image since it was captured,
+// ordered from the camera's proof to the proof of the last edit.
+// Unlike a recursive history proof, the chain grows by one proof per edit.
+type ProofChain struct {
+	Proofs []Proof
+}
+
+// Add the proof of the latest transformation at the end of the chain.
+func (chain *ProofChain) Append(proof Proof) {
+	chain.Proofs = append(chain.Proofs, proof)
+}
+
+// Return the proof of the latest transformation in the chain.
+func (chain ProofChain) Last() (Proof, error) {
+	if len(chain.Proofs) == 0 {
+		return Proof{}, errors.New("EMPTY PROOF CHAIN")
+	}
+
+	return chain.Proofs[len(chain.Proofs)-1], nil
+}
+
+// Verify every proof of the chain against the image the verifier is looking at:
+//   - each Groth16 proof verifies with the trusted verifying key of its transformation type, in vks,
+//   - the output digest of each step is the input digest of the next one,
+//     and the output digest of the last step is the digest of img,
+//   - the input digest of the first step is the origin digest, signed by one of the trusted camera keys,
+//     and every step carries that same key, signature and origin digest.
+func VerifyChain(chain ProofChain, img image.Image, vks VerifyingKeys, trustedKeys []signature.PublicKey) (bool, error) {
+	if len(chain.Proofs) == 0 {
+		return false, errors.New("EMPTY PROOF CHAIN")
+	}
+	first := chain.Proofs[0]
+
+	// The chain must start from the image signed by a trusted camera
+	if !bytes.Equal(first.Input_Digest, first.Origin_Digest) {
+		return false, errors.New("INVALID PROOF CHAIN: IT DOES NOT START FROM THE SIGNED IMAGE")
+	}
+	if !isTrusted(first.VK.PublicKey, trustedKeys) {
+		return false, errors.New("INVALID PROOF CHAIN: THE CAMERA KEY IS NOT TRUSTED")
+	}
+
+	firstVK, err := vks.of(first)
+	if err != nil {
+		return false, fmt.Errorf("INVALID PROOF CHAIN: STEP 0: %w", err)
+	}
+	curve := firstVK.CurveID()
+
+	// The last step outputs the image the verifier is looking at
+	outputDigest, err := img.Digest(curve)
+	if err != nil {
+		return false, err
+	}
+
+	for i, proof := range chain.Proofs {
+		// Every step is a proof over the same curve, about the same signed image
+		vk, err := vks.of(proof)
+		if err != nil {
+			return false, fmt.Errorf("INVALID PROOF CHAIN: STEP %d: %w", i, err)
+		}
+		if vk.CurveID() != curve {
+			return false, fmt.Errorf("INVALID PROOF CHAIN: STEP %d IS NOT OVER %s", i, curve)
+		}
+		if !proof.VK.PublicKey.Equal(first.VK.PublicKey) ||
+			!bytes.Equal(proof.Signature, first.Signature) ||
+			!bytes.Equal(proof.Origin_Digest, first.Origin_Digest) {
+			return false, fmt.Errorf("INVALID PROOF CHAIN: STEP %d IS NOT ABOUT THE SAME SIGNED IMAGE", i)
+		}
+
+		// Each step outputs the input of the next step
+		stepOutput := outputDigest
+		if i < len(chain.Proofs)-1 {
+			stepOutput = chain.Proofs[i+1].Input_Digest
+		}
+
+		if err := verifyTransformation(proof, stepOutput, vks); err != nil {
+			return false, fmt.Errorf("INVALID PROOF CHAIN: STEP %d: %w", i, err)
+		}
+	}
+
+	return true, nil
+}
+
+func isTrusted(publicKey signature.PublicKey, trustedKeys []signature.PublicKey) bool {
+	if publicKey == nil {
+		return false
+	}
+
+	for _, trustedKey := range trustedKeys {
+		if publicKey.Equal(trustedKey) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+package circuits
+
+import (
+	"testing"
+
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+// Prove the identity transformation of img, the output of the step proven by proof_in.
+func proveIdentity(assert *test.Assert, prover *Prover, img image.Image, proof_in Proof) Proof {
+	publicInputs, err := NewTransformationInputs(prover.Curve(), "identity", proof_in, img, img)
+	assert.NoError(err)
+	frImage, err := img.ToFrImage(prover.Curve(), prover.MaxImageSize())
+	assert.NoError(err)
+
+	proof, err := prover.Prove("identity", &IdentityCircuit{PublicInputs: publicInputs, FrImage: frImage}, img, proof_in)
+	assert.NoError(err)
+	return proof
+}
+
+// Prove, with keys of its own, that the step proven by proof_in output img as an identity
+// transformation, without proving anything.
+func forgeIdentity(assert *test.Assert, curve ecc.ID, img_in image.Image, img image.Image, proof_in Proof) Proof {
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &forgedCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	publicInputs, err := NewTransformationInputs(curve, "identity", proof_in, img_in, img)
+	assert.NoError(err)
+	fullWitness, err := frontend.NewWitness(&forgedCircuit{PublicInputs: publicInputs}, curve.ScalarField())
+	assert.NoError(err)
+	pcdProof, err := groth16.Prove(ccs, pk, fullWitness)
+	assert.NoError(err)
+
+	// The forged proof verifies with the forger's key
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(pcdProof, vk, publicWitness))
+
+	digest, err := img_in.Digest(curve)
+	assert.NoError(err)
+	return Proof{
+		PCD_Proof:      pcdProof,
+		Transformation: "identity",
+		Signature:      proof_in.Signature,
+		Origin_Digest:  proof_in.Origin_Digest,
+		Input_Digest:   digest,
+		VK:             VK{VeriKey: vk, PublicKey: proof_in.VK.PublicKey},
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := Setup(4, ecc.BN254.ScalarField(), "identity")
+	assert.NoError(err)
+	prover, err := NewProver(params)
+	assert.NoError(err)
+	vks := params.VerifyingKeys()
+
+	sk, err := NewSecretKey(params.Curve)
+	assert.NoError(err)
+	trustedKeys := []signature.PublicKey{sk.Public()}
+
+	img, err := image.NewImageOfSize("random", 2, 2)
+	assert.NoError(err)
+	capture, err := NewCaptureProof(img, sk, params.Curve)
+	assert.NoError(err)
+
+	// A chain of two steps verifies
+	var chain ProofChain
+	chain.Append(proveIdentity(assert, prover, img, capture))
+	chain.Append(proveIdentity(assert, prover, img, chain.Proofs[0]))
+	valid, err := VerifyChain(chain, img, vks, trustedKeys)
+	assert.NoError(err)
+	assert.True(valid)
+
+	// Not when a step transforms another image than the output of the previous one
+	other, err := image.NewImageOfSize("random", 2, 2)
+	assert.NoError(err)
+	broken := ProofChain{Proofs: []Proof{chain.Proofs[0], proveIdentity(assert, prover, other, chain.Proofs[0])}}
+	_, err = VerifyChain(broken, other, vks, trustedKeys)
+	assert.Error(err)
+
+	// Nor when the camera is not trusted
+	untrusted, err := NewSecretKey(params.Curve)
+	assert.NoError(err)
+	_, err = VerifyChain(chain, img, vks, []signature.PublicKey{untrusted.Public()})
+	assert.Error(err)
+
+	// Nor when a step was proven with keys the verifier does not trust,
+	// whether it carries the forger's verifying key or claims the trusted one
+	forged := forgeIdentity(assert, params.Curve, img, other, chain.Proofs[0])
+	_, err = VerifyChain(ProofChain{Proofs: []Proof{chain.Proofs[0], forged}}, other, vks, trustedKeys)
+	assert.Error(err)
+	forged.VK.VeriKey = vks["identity"]
+	_, err = VerifyChain(ProofChain{Proofs: []Proof{chain.Proofs[0], forged}}, other, vks, trustedKeys)
+	assert.Error(err)
+
+	// The same goes for a single proof, which only verifies for those who trust the forger's key
+	forged = forgeIdentity(assert, params.Curve, img, other, capture)
+	_, err = Verifier(forged, other, vks)
+	assert.Error(err)
+	valid, err = Verifier(forged, other, VerifyingKeys{"identity": forged.VK.VeriKey})
+	assert.NoError(err)
+	assert.True(valid)
+}
@@ -0,0 +1,312 @@
+package circuits
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// Version of the proof encoding, bumped whenever EncodedProof changes.
+const ProofVersion = 2
+
+// First bytes of a binary encoded proof.
+var proofMagic = [4]byte{'P', 'G', 'K', 'P'}
+
+// A Proof in a form that can be stored and shipped between machines. The verifying key
+// is only referenced by its fingerprint, the verifier provides the key itself when decoding.
+type EncodedProof struct {
+	Version        uint16 `json:"version"`
+	Transformation string `json:"transformation"`
+	Curve          string `json:"curve"`
+	PublicKey      []byte `json:"public_key"`
+	Signature      []byte `json:"signature"`
+	OriginDigest   []byte `json:"origin_digest"`
+	InputDigest    []byte `json:"input_digest"`
+	PublicWitness  []byte `json:"public_witness"`
+	PCDProof       []byte `json:"pcd_proof"`
+	VKFingerprint  []byte `json:"vk_fingerprint"`
+	Parameters     []int  `json:"parameters,omitempty"`
+}
+
+// Return the SHA-256 of the serialized verifying key.
+func VKFingerprint(vk groth16.VerifyingKey) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := vk.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return sum[:], nil
+}
+
+// Encode a proof. The proof must hold its PCD proof, verifying key and the camera's public key.
+func EncodeProof(proof Proof) (EncodedProof, error) {
+	if proof.PCD_Proof == nil || proof.VK.VeriKey == nil || proof.VK.PublicKey == nil {
+		return EncodedProof{}, errors.New("INCOMPLETE PROOF")
+	}
+
+	curve := proof.VK.VeriKey.CurveID()
+	if proof.PCD_Proof.CurveID() != curve {
+		return EncodedProof{}, errors.New("PROOF AND VERIFYING KEY ARE ON DIFFERENT CURVES")
+	}
+
+	fingerprint, err := VKFingerprint(proof.VK.VeriKey)
+	if err != nil {
+		return EncodedProof{}, err
+	}
+
+	var pcdProof bytes.Buffer
+	if _, err := proof.PCD_Proof.WriteTo(&pcdProof); err != nil {
+		return EncodedProof{}, err
+	}
+
+	var publicWitness []byte
+	if proof.Public_Witness != nil {
+		publicWitness, err = proof.Public_Witness.MarshalBinary()
+		if err != nil {
+			return EncodedProof{}, err
+		}
+	}
+
+	return EncodedProof{
+		Version:        ProofVersion,
+		Transformation: proof.Transformation,
+		Curve:          curve.String(),
+		PublicKey:      proof.VK.PublicKey.Bytes(),
+		Signature:      proof.Signature,
+		OriginDigest:   proof.Origin_Digest,
+		InputDigest:    proof.Input_Digest,
+		PublicWitness:  publicWitness,
+		PCDProof:       pcdProof.Bytes(),
+		VKFingerprint:  fingerprint,
+		Parameters:     proof.Parameters,
+	}, nil
+}
+
+// Decode the proof, using the verifying key among vks whose fingerprint it references.
+func (encoded EncodedProof) Decode(vks ...groth16.VerifyingKey) (Proof, error) {
+	if encoded.Version != ProofVersion {
+		return Proof{}, fmt.Errorf("UNSUPPORTED PROOF VERSION %d", encoded.Version)
+	}
+
+	curve, err := ecc.IDFromString(encoded.Curve)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	vk, err := findVK(curve, encoded.VKFingerprint, vks)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	publicKey, err := NewPublicKey(SigningCurve(curve), encoded.PublicKey)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	pcdProof := groth16.NewProof(curve)
+	if _, err := pcdProof.ReadFrom(bytes.NewReader(encoded.PCDProof)); err != nil {
+		return Proof{}, err
+	}
+
+	var publicWitness witness.Witness
+	if len(encoded.PublicWitness) > 0 {
+		publicWitness, err = witness.New(curve.ScalarField())
+		if err != nil {
+			return Proof{}, err
+		}
+		if err := publicWitness.UnmarshalBinary(encoded.PublicWitness); err != nil {
+			return Proof{}, err
+		}
+	}
+
+	return Proof{
+		PCD_Proof:      pcdProof,
+		Transformation: encoded.Transformation,
+		Signature:      encoded.Signature,
+		Origin_Digest:  encoded.OriginDigest,
+		Input_Digest:   encoded.InputDigest,
+		Parameters:     encoded.Parameters,
+		Public_Witness: publicWitness,
+		VK:             VK{VeriKey: vk, PublicKey: publicKey},
+	}, nil
+}
+
+// Return the verifying key over the given curve with the given fingerprint.
+func findVK(curve ecc.ID, fingerprint []byte, vks []groth16.VerifyingKey) (groth16.VerifyingKey, error) {
+	for _, vk := range vks {
+		if vk == nil || vk.CurveID() != curve {
+			continue
+		}
+
+		vkFingerprint, err := VKFingerprint(vk)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(vkFingerprint, fingerprint) {
+			return vk, nil
+		}
+	}
+
+	return nil, fmt.Errorf("UNKNOWN VERIFYING KEY %x", fingerprint)
+}
+
+// Binary encoding: the magic bytes and the version, followed by every field
+// as a big-endian uint32 length and its bytes, in the order of EncodedProof.
+// The parameters are encoded as big-endian int64s.
+func (encoded EncodedProof) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(proofMagic[:])
+	binary.Write(&buf, binary.BigEndian, encoded.Version)
+
+	writeField(&buf, []byte(encoded.Transformation))
+	writeField(&buf, []byte(encoded.Curve))
+	for _, field := range encoded.fields() {
+		writeField(&buf, *field)
+	}
+
+	parameters := make([]byte, 0, 8*len(encoded.Parameters))
+	for _, parameter := range encoded.Parameters {
+		parameters = binary.BigEndian.AppendUint64(parameters, uint64(int64(parameter)))
+	}
+	writeField(&buf, parameters)
+
+	return buf.Bytes(), nil
+}
+
+// Decode the binary encoding written by MarshalBinary.
+func (encoded *EncodedProof) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil || magic != proofMagic {
+		return errors.New("NOT AN ENCODED PROOF")
+	}
+
+	var decoded EncodedProof
+	if err := binary.Read(r, binary.BigEndian, &decoded.Version); err != nil {
+		return err
+	}
+	if decoded.Version != ProofVersion {
+		return fmt.Errorf("UNSUPPORTED PROOF VERSION %d", decoded.Version)
+	}
+
+	transformation, err := readField(r)
+	if err != nil {
+		return err
+	}
+	curve, err := readField(r)
+	if err != nil {
+		return err
+	}
+	decoded.Transformation, decoded.Curve = string(transformation), string(curve)
+
+	for _, field := range decoded.fields() {
+		if *field, err = readField(r); err != nil {
+			return err
+		}
+	}
+
+	parameters, err := readField(r)
+	if err != nil {
+		return err
+	}
+	if len(parameters)%8 != 0 {
+		return errors.New("INVALID ENCODED PARAMETERS")
+	}
+	for i := 0; i < len(parameters); i += 8 {
+		decoded.Parameters = append(decoded.Parameters, int(int64(binary.BigEndian.Uint64(parameters[i:]))))
+	}
+
+	if r.Len() != 0 {
+		return errors.New("TRAILING BYTES AFTER ENCODED PROOF")
+	}
+
+	*encoded = decoded
+	return nil
+}
+
+// Return pointers to the byte fields, in encoding order.
+func (encoded *EncodedProof) fields() []*[]byte {
+	return []*[]byte{
+		&encoded.PublicKey,
+		&encoded.Signature,
+		&encoded.OriginDigest,
+		&encoded.InputDigest,
+		&encoded.PublicWitness,
+		&encoded.PCDProof,
+		&encoded.VKFingerprint,
+	}
+}
+
+// Write a length-prefixed field.
+func writeField(buf *bytes.Buffer, field []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(field)))
+	buf.Write(field)
+}
+
+// Read a length-prefixed field.
+func readField(r *bytes.Reader) ([]byte, error) {
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	if int64(length) > int64(r.Len()) {
+		return nil, errors.New("TRUNCATED ENCODED PROOF")
+	}
+
+	field := make([]byte, length)
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, err
+	}
+
+	return field, nil
+}
+
+// Write a proof in the binary encoding.
+func MarshalProof(proof Proof) ([]byte, error) {
+	encoded, err := EncodeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return encoded.MarshalBinary()
+}
+
+// Read a proof in the binary encoding, vks are the verifying keys the verifier trusts.
+func UnmarshalProof(data []byte, vks ...groth16.VerifyingKey) (Proof, error) {
+	var encoded EncodedProof
+	if err := encoded.UnmarshalBinary(data); err != nil {
+		return Proof{}, err
+	}
+
+	return encoded.Decode(vks...)
+}
+
+// Write a proof in the JSON encoding.
+func MarshalProofJSON(proof Proof) ([]byte, error) {
+	encoded, err := EncodeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encoded)
+}
+
+// Read a proof in the JSON encoding, vks are the verifying keys the verifier trusts.
+func UnmarshalProofJSON(data []byte, vks ...groth16.VerifyingKey) (Proof, error) {
+	var encoded EncodedProof
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return Proof{}, err
+	}
+
+	return encoded.Decode(vks...)
+}
@@ -0,0 +1,87 @@
+package circuits
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+// A circuit small enough to run a real setup in tests.
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+// Return a proof of the square circuit and another verifying key of the same curve.
+func newSquareProof(assert *test.Assert, curve ecc.ID) (Proof, groth16.VerifyingKey) {
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	_, otherVK, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	fullWitness, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, curve.ScalarField())
+	assert.NoError(err)
+	pcdProof, err := groth16.Prove(ccs, pk, fullWitness)
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+
+	sk, err := NewSecretKey(curve)
+	assert.NoError(err)
+
+	return Proof{
+		PCD_Proof:      pcdProof,
+		Transformation: "identity",
+		Signature:      []byte{1, 2, 3},
+		Origin_Digest:  []byte{4, 5},
+		Input_Digest:   []byte{6},
+		Parameters:     []int{-20, 3},
+		Public_Witness: publicWitness,
+		VK:             VK{VeriKey: vk, PublicKey: sk.Public()},
+	}, otherVK
+}
+
+func TestProofEncodingRoundTrip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, curve := range supportedCurves {
+		proof, otherVK := newSquareProof(assert, curve)
+
+		binaryProof, err := MarshalProof(proof)
+		assert.NoError(err)
+		jsonProof, err := MarshalProofJSON(proof)
+		assert.NoError(err)
+
+		// Both encodings decode to a proof that verifies and encodes to the same bytes.
+		fromBinary, err := UnmarshalProof(binaryProof, otherVK, proof.VK.VeriKey)
+		assert.NoError(err)
+		fromJSON, err := UnmarshalProofJSON(jsonProof, proof.VK.VeriKey)
+		assert.NoError(err)
+		for _, decoded := range []Proof{fromBinary, fromJSON} {
+			assert.True(decoded.VK.PublicKey.Equal(proof.VK.PublicKey))
+			assert.Equal(proof.Parameters, decoded.Parameters)
+			assert.NoError(groth16.Verify(decoded.PCD_Proof, decoded.VK.VeriKey, decoded.Public_Witness))
+			reencoded, err := MarshalProof(decoded)
+			assert.NoError(err)
+			assert.Equal(binaryProof, reencoded)
+		}
+
+		// A proof is refused without its verifying key, or with a different version.
+		_, err = UnmarshalProof(binaryProof, otherVK)
+		assert.Error(err)
+		binaryProof[5]++
+		_, err = UnmarshalProof(binaryProof, proof.VK.VeriKey)
+		assert.Error(err)
+	}
+}
@@ -0,0 +1,100 @@
+package circuits
+
+import (
+	"fmt"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// Proves transformations with the constraint systems and proving keys of the public parameters.
+// Every circuit is compiled once, when the prover is created, and never modified afterwards
+// so a single prover can be shared by concurrent goroutines.
+type Prover struct {
+	curve        ecc.ID
+	maxImageSize int
+	keys         map[string]TransformationKeys
+}
+
+// Create a prover for every transformation of the public parameters, compiling the
+// circuits whose constraint system is not part of the parameters.
+func NewProver(params PublicParams) (*Prover, error) {
+	prover := &Prover{curve: params.Curve, maxImageSize: params.MaxImageSize, keys: make(map[string]TransformationKeys, len(params.Keys))}
+
+	for transformationType, keys := range params.Keys {
+		if keys.Compiled == nil {
+			circuit, err := CircuitOf(transformationType, params.MaxImageSize)
+			if err != nil {
+				return nil, err
+			}
+
+			keys.Compiled, err = frontend.Compile(params.Curve.ScalarField(), r1cs.NewBuilder, circuit)
+			if err != nil {
+				return nil, err
+			}
+		}
+		prover.keys[transformationType] = keys
+	}
+
+	return prover, nil
+}
+
+// Return the curve the prover's keys were generated on.
+func (prover *Prover) Curve() ecc.ID {
+	return prover.curve
+}
+
+// Return the maximum number of pixels of the images the prover's circuits accept.
+func (prover *Prover) MaxImageSize() int {
+	return prover.maxImageSize
+}
+
+// Prove that assignment, a circuit of the given transformation type, transforms img_in.
+// The camera's public key, signature and origin digest are carried forward from proof_in.
+// parameters are the public parameters of the transformation assigned in assignment.
+func (prover *Prover) Prove(transformationType string, assignment frontend.Circuit, img_in image.Image, proof_in Proof, parameters ...int) (Proof, error) {
+	keys, ok := prover.keys[transformationType]
+	if !ok {
+		return Proof{}, fmt.Errorf("NO KEYS FOR TRANSFORMATION TYPE %q", transformationType)
+	}
+	if len(parameters) != NbParameters(transformationType) {
+		return Proof{}, fmt.Errorf("EXPECTED %d PARAMETERS FOR TRANSFORMATION TYPE %q", NbParameters(transformationType), transformationType)
+	}
+
+	// Create the secret witness from the circuit
+	secret_witness, err := frontend.NewWitness(assignment, prover.curve.ScalarField())
+	if err != nil {
+		return Proof{}, err
+	}
+
+	// Prove the secret witness adheres to the compliance predicate, using the cached proving key
+	pcd_proof, err := groth16.Prove(keys.Compiled, keys.ProvKey, secret_witness, ProverOptions(prover.curve)...)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	// Create a public witness
+	publicWitness, err := secret_witness.Public()
+	if err != nil {
+		return Proof{}, err
+	}
+
+	digest, err := img_in.Digest(prover.curve)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	return Proof{
+		PCD_Proof:      pcd_proof,
+		Transformation: transformationType,
+		Signature:      proof_in.Signature,
+		Origin_Digest:  proof_in.Origin_Digest,
+		Input_Digest:   digest,
+		Parameters:     parameters,
+		Public_Witness: publicWitness,
+		VK:             VK{VeriKey: keys.VeriKey, PublicKey: proof_in.VK.PublicKey},
+	}, nil
+}
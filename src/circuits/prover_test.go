@@ -0,0 +1,70 @@
+package circuits
+
+import (
+	"sync"
+	"testing"
+
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+)
+
+// Run with -race: a single prover is shared by concurrent goroutines.
+func TestProverConcurrentProofs(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := Setup(4, ecc.BN254.ScalarField(), "identity")
+	assert.NoError(err)
+	prover, err := NewProver(params)
+	assert.NoError(err)
+	sk, err := NewSecretKey(params.Curve)
+	assert.NoError(err)
+
+	// Every goroutine proves its own picture
+	const nbProofs = 4
+	images := make([]image.Image, nbProofs)
+	captures := make([]Proof, nbProofs)
+	for i := range images {
+		images[i], err = image.NewImageOfSize("random", 2, 2)
+		assert.NoError(err)
+		captures[i], err = NewCaptureProof(images[i], sk, params.Curve)
+		assert.NoError(err)
+	}
+
+	proofs := make([]Proof, nbProofs)
+	errs := make([]error, nbProofs)
+	var wg sync.WaitGroup
+	for i := range proofs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			publicInputs, err := NewTransformationInputs(params.Curve, "identity", captures[i], images[i], images[i])
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			frImage, err := images[i].ToFrImage(params.Curve, params.MaxImageSize)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			proofs[i], errs[i] = prover.Prove("identity", &IdentityCircuit{PublicInputs: publicInputs, FrImage: frImage}, images[i], captures[i])
+		}(i)
+	}
+	wg.Wait()
+
+	// Each proof verifies against its own picture only
+	vks := params.VerifyingKeys()
+	for i, proof := range proofs {
+		assert.NoError(errs[i])
+
+		valid, err := Verifier(proof, images[i], vks)
+		assert.NoError(err)
+		assert.True(valid)
+
+		_, err = Verifier(proof, images[(i+1)%nbProofs], vks)
+		assert.Error(err)
+	}
+}
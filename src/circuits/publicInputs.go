@@ -0,0 +1,159 @@
+package circuits
+
+import (
+	"errors"
+	"fmt"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+// The public inputs of every transformation circuit.
+// Circuits embed this struct first, and their only other public fields are the
+// parameters of their transformation (see NbParameters), such as the brightness delta.
+// So the public witness of any transformation can be rebuilt from it and those parameters.
+//
+// The camera signs the digest of the image it captured (the origin). Every
+// transformation verifies that signature, and exposes the digests of the image
+// it transformed and of the image it produced, so that consecutive proofs can
+// be linked back to the signed capture, along with the code of its transformation type.
+type PublicInputs struct {
+	PublicKey       eddsa.PublicKey   `gnark:",public"`
+	EdDSA_Signature eddsa.Signature   `gnark:",public"`
+	OriginDigest    frontend.Variable `gnark:",public"` // Digest of the image signed by the camera
+	InputDigest     frontend.Variable `gnark:",public"` // Digest of the image being transformed
+	OutputDigest    frontend.Variable `gnark:",public"` // Digest of the transformed image
+	Transformation  frontend.Variable `gnark:",public"` // Code of the transformation type
+}
+
+// AssertSignedInput asserts what every transformation circuit proves first: it is the circuit of the
+// transformation of the given code, the camera signed the origin digest and img is the image of the input digest.
+func (inputs *PublicInputs) AssertSignedInput(api frontend.API, code frontend.Variable, img image.FrImage) error {
+	api.AssertIsEqual(inputs.Transformation, code)
+
+	if err := inputs.VerifySignature(api); err != nil {
+		return err
+	}
+
+	return assertDigest(api, inputs.InputDigest, img)
+}
+
+// AssertOutput asserts that img, the image produced by the transformation, is the image of the output digest.
+func (inputs *PublicInputs) AssertOutput(api frontend.API, img image.FrImage) error {
+	return assertDigest(api, inputs.OutputDigest, img)
+}
+
+// Verify the camera's signature covers the origin digest.
+func (inputs *PublicInputs) VerifySignature(api frontend.API) error {
+	return VerifyDigestSignature(api, inputs.PublicKey, inputs.EdDSA_Signature, inputs.OriginDigest)
+}
+
+// Positions of the digests in the public witness of a transformation circuit,
+// after the public key (A.X, A.Y) and the signature (R.X, R.Y, S).
+const (
+	originDigestIdx   = 5
+	inputDigestIdx    = 6
+	outputDigestIdx   = 7
+	transformationIdx = 8
+)
+
+// Assign the camera's public key, its signature over the origin digest, the input & output digests
+// and the code of the transformation type.
+func NewPublicInputs(curve ecc.ID, transformationType string, publicKey signature.PublicKey, digSig []byte, originDigest, inputDigest, outputDigest []byte) (PublicInputs, error) {
+	edID, err := EdwardsID(curve)
+	if err != nil {
+		return PublicInputs{}, err
+	}
+
+	code, err := TransformationCode(transformationType)
+	if err != nil {
+		return PublicInputs{}, err
+	}
+
+	// Assign the PK & signature to their eddsa equivilant
+	var eddsa_digSig eddsa.Signature
+	var eddsa_PK eddsa.PublicKey
+
+	eddsa_digSig.Assign(edID, digSig)
+	eddsa_PK.Assign(edID, publicKey.Bytes())
+
+	return PublicInputs{
+		PublicKey:       eddsa_PK,
+		EdDSA_Signature: eddsa_digSig,
+		OriginDigest:    originDigest,
+		InputDigest:     inputDigest,
+		OutputDigest:    outputDigest,
+		Transformation:  code,
+	}, nil
+}
+
+// Assign the public inputs of a transformation of img_in into img_out. The camera's public key,
+// its signature and the origin digest are carried forward from the incoming proof, so the
+// prover never needs the camera's secret key.
+func NewTransformationInputs(curve ecc.ID, transformationType string, proof_in Proof, img_in image.Image, img_out image.Image) (PublicInputs, error) {
+	if proof_in.VK.PublicKey == nil || len(proof_in.Signature) == 0 || len(proof_in.Origin_Digest) == 0 {
+		return PublicInputs{}, errors.New("INCOMING PROOF HAS NO SIGNED ORIGIN")
+	}
+
+	inputDigest, err := img_in.Digest(curve)
+	if err != nil {
+		return PublicInputs{}, err
+	}
+
+	outputDigest, err := img_out.Digest(curve)
+	if err != nil {
+		return PublicInputs{}, err
+	}
+
+	return NewPublicInputs(curve, transformationType, proof_in.VK.PublicKey, proof_in.Signature, proof_in.Origin_Digest, inputDigest, outputDigest)
+}
+
+// Build the public witness a transformation proof over the given curve must verify against,
+// parameters are the public parameters of the transformation.
+func NewPublicWitness(curve ecc.ID, transformationType string, publicKey signature.PublicKey, digSig []byte, originDigest, inputDigest, outputDigest []byte, parameters ...int) (witness.Witness, error) {
+	if len(parameters) != NbParameters(transformationType) {
+		return nil, fmt.Errorf("EXPECTED %d PARAMETERS FOR TRANSFORMATION TYPE %q", NbParameters(transformationType), transformationType)
+	}
+
+	publicInputs, err := NewPublicInputs(curve, transformationType, publicKey, digSig, originDigest, inputDigest, outputDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every transformation circuit shares the same public inputs, so a circuit holding only
+	// them, and the parameters if any, has the public witness of any transformation circuit.
+	var circuit frontend.Circuit = &publicCircuit{PublicInputs: publicInputs}
+	if len(parameters) > 0 {
+		parametrized := parametrizedCircuit{PublicInputs: publicInputs, Parameters: make([]frontend.Variable, len(parameters))}
+		for i, parameter := range parameters {
+			parametrized.Parameters[i] = parameter
+		}
+		circuit = &parametrized
+	}
+
+	return frontend.NewWitness(circuit, curve.ScalarField(), frontend.PublicOnly())
+}
+
+// The public inputs alone, only used to build public witnesses.
+type publicCircuit struct {
+	PublicInputs
+}
+
+func (circuit *publicCircuit) Define(api frontend.API) error {
+	return nil
+}
+
+// The public inputs followed by the public parameters of a transformation, only used to build public witnesses.
+// gnark ignores empty slices with a warning, transformations without parameters use a publicCircuit.
+type parametrizedCircuit struct {
+	PublicInputs
+	Parameters []frontend.Variable `gnark:",public"`
+}
+
+func (circuit *parametrizedCircuit) Define(api frontend.API) error {
+	return nil
+}
@@ -0,0 +1,218 @@
+package circuits
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	"github.com/consensys/gnark/std/math/emulated"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+)
+
+// Transformation proofs over BLS12-377 are verified by a circuit over BW6-761,
+// whose scalar field is the base field of BLS12-377 (a 2-chain of curves).
+type (
+	innerScalar = sw_bls12377.ScalarField
+	innerG1     = sw_bls12377.G1Affine
+	innerG2     = sw_bls12377.G2Affine
+	innerGT     = sw_bls12377.GT
+)
+
+// One step of an image's edit history: a transformation proof and its public witness.
+type RecursiveStep struct {
+	Proof   stdgroth16.Proof[innerG1, innerG2]
+	Witness stdgroth16.Witness[innerScalar]
+	vk      stdgroth16.VerifyingKey[innerG1, innerG2, innerGT] `gnark:"-"` // Fixed when compiling the circuit
+}
+
+// This circuit aggregates, in one shot, the transformation proofs of every step
+// of an image's edit history, and links them from the signed capture to the final image.
+// Its public inputs are the ones of a single transformation proof over the
+// whole history, followed by the public parameters of every step in order,
+// so the history ships as one constant-size proof.
+//
+// This is not incremental PCD: no step verifies the proof of the previous step. A 2-chain
+// only allows one level of recursion, so a RecursiveCircuit can not verify another
+// RecursiveCircuit proof, and editing the image once more means aggregating every step
+// proof again. The steps and their verifying keys are fixed when compiling, so keys are
+// generated for a given sequence of transformation types, named by HistoryType.
+type RecursiveCircuit struct {
+	History    stdgroth16.Witness[innerScalar] `gnark:",public"` // The PublicInputs of the whole history
+	Parameters []emulated.Element[innerScalar] `gnark:",public"` // The parameters of every step, see NbParameters
+	Steps      []RecursiveStep
+}
+
+// Number of public inputs shared by every transformation circuit, the PublicInputs.
+const nbPublicInputs = transformationIdx + 1
+
+// Return the transformation type of the history proofs of the given sequence of transformation types,
+// the verifier trusts the recursive verifying key of each history under this type.
+func HistoryType(history []string) string {
+	return "history(" + strings.Join(history, ",") + ")"
+}
+
+type RecursiveKeys struct {
+	Compiled constraint.ConstraintSystem
+	ProvKey  groth16.ProvingKey
+	VeriKey  groth16.VerifyingKey
+	History  []string // The transformation type of each step
+}
+
+// Return the transformation type of the history proofs made with the keys.
+func (keys RecursiveKeys) Type() string {
+	return HistoryType(keys.History)
+}
+
+// Allocate a RecursiveCircuit for steps proven with the given BLS12-377 verifying keys.
+// The constraint systems of the steps are used to size the proofs and witnesses.
+func NewRecursiveCircuit(stepCircuits []constraint.ConstraintSystem, stepVKs []groth16.VerifyingKey) (RecursiveCircuit, error) {
+	if len(stepCircuits) == 0 || len(stepCircuits) != len(stepVKs) {
+		return RecursiveCircuit{}, errors.New("INVALID HISTORY: EXPECTED ONE VERIFYING KEY PER STEP")
+	}
+
+	// The history has the public inputs of a transformation without parameters,
+	// the parameters of the steps follow it
+	circuit := RecursiveCircuit{
+		History: stdgroth16.Witness[innerScalar]{Public: make([]emulated.Element[innerScalar], nbPublicInputs)},
+		Steps:   make([]RecursiveStep, len(stepCircuits)),
+	}
+
+	for i := range stepCircuits {
+		// The public variables of a constraint system count the constant 1
+		nbParameters := stepCircuits[i].GetNbPublicVariables() - 1 - nbPublicInputs
+		if nbParameters < 0 {
+			return RecursiveCircuit{}, errors.New("INVALID HISTORY: A STEP IS NOT A TRANSFORMATION CIRCUIT")
+		}
+		circuit.Parameters = append(circuit.Parameters, make([]emulated.Element[innerScalar], nbParameters)...)
+
+		vk, err := stdgroth16.ValueOfVerifyingKeyFixed[innerG1, innerG2, innerGT](stepVKs[i])
+		if err != nil {
+			return RecursiveCircuit{}, err
+		}
+
+		circuit.Steps[i] = RecursiveStep{
+			Proof:   stdgroth16.PlaceholderProof[innerG1, innerG2](stepCircuits[i]),
+			Witness: stdgroth16.PlaceholderWitness[innerScalar](stepCircuits[i]),
+			vk:      vk,
+		}
+	}
+
+	return circuit, nil
+}
+
+func (circuit *RecursiveCircuit) Define(api frontend.API) error {
+	if len(circuit.Steps) == 0 {
+		return errors.New("INVALID HISTORY: NO STEPS")
+	}
+
+	// The digests and keys of the steps are BLS12-377 scalars
+	scalarApi, err := emulated.NewField[innerScalar](api)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := stdgroth16.NewVerifier[innerScalar, innerG1, innerG2, innerGT](api)
+	if err != nil {
+		return err
+	}
+
+	history := circuit.History.Public
+	if len(history) != nbPublicInputs {
+		return errors.New("INVALID HISTORY: IT DOES NOT HAVE THE PUBLIC INPUTS OF A TRANSFORMATION")
+	}
+
+	// The history starts from the image signed by the camera
+	scalarApi.AssertIsEqual(&history[transformationIdx], scalarApi.NewElement(HistoryCode))
+	scalarApi.AssertIsEqual(&history[inputDigestIdx], &history[originDigestIdx])
+
+	previousOutput := &history[inputDigestIdx]
+	parameters := circuit.Parameters
+	for i := range circuit.Steps {
+		step := &circuit.Steps[i]
+
+		// Verify the transformation proof of the step
+		if err := verifier.AssertProof(step.vk, step.Proof, step.Witness); err != nil {
+			return err
+		}
+
+		// Every step carries the camera's public key, signature and origin digest
+		public := step.Witness.Public
+		for j := 0; j <= originDigestIdx; j++ {
+			scalarApi.AssertIsEqual(&public[j], &history[j])
+		}
+
+		// And transforms the image output by the previous step
+		scalarApi.AssertIsEqual(&public[inputDigestIdx], previousOutput)
+		previousOutput = &public[outputDigestIdx]
+
+		// With the parameters of the history, which follow the public inputs of the step
+		stepParameters := public[nbPublicInputs:]
+		if len(stepParameters) > len(parameters) {
+			return errors.New("INVALID HISTORY: MISSING PARAMETERS")
+		}
+		for j := range stepParameters {
+			scalarApi.AssertIsEqual(&stepParameters[j], &parameters[j])
+		}
+		parameters = parameters[len(stepParameters):]
+	}
+	if len(parameters) != 0 {
+		return errors.New("INVALID HISTORY: TOO MANY PARAMETERS")
+	}
+
+	// The last step outputs the image of the history
+	scalarApi.AssertIsEqual(previousOutput, &history[outputDigestIdx])
+
+	return nil
+}
+
+// Compile a RecursiveCircuit for the given sequence of transformation types and generate its keys.
+// The verifying keys are the BLS12-377 keys each step is proven with, for images of at most max_image_size pixels.
+func RecursiveSetup(max_image_size int, history []string, stepVKs []groth16.VerifyingKey) (RecursiveKeys, error) {
+	// Compile the step circuits over BLS12-377 to size the recursive circuit
+	stepCircuits := make([]constraint.ConstraintSystem, len(history))
+	for i, transformationType := range history {
+		stepCircuit, err := CircuitOf(transformationType, max_image_size)
+		if err != nil {
+			return RecursiveKeys{}, err
+		}
+
+		stepCircuits[i], err = frontend.Compile(ecc.BLS12_377.ScalarField(), r1cs.NewBuilder, stepCircuit)
+		if err != nil {
+			return RecursiveKeys{}, err
+		}
+	}
+
+	circuit, err := NewRecursiveCircuit(stepCircuits, stepVKs)
+	if err != nil {
+		return RecursiveKeys{}, err
+	}
+
+	// The recursive circuit is over BW6-761
+	compiled, err := frontend.Compile(ecc.BW6_761.ScalarField(), r1cs.NewBuilder, &circuit)
+	if err != nil {
+		return RecursiveKeys{}, err
+	}
+
+	provingKey, verifyingKey, err := groth16.Setup(compiled)
+	if err != nil {
+		return RecursiveKeys{}, err
+	}
+
+	return RecursiveKeys{Compiled: compiled, ProvKey: provingKey, VeriKey: verifyingKey, History: history}, nil
+}
+
+// Return the public parameters of the steps of a history as BLS12-377 scalars,
+// negative parameters such as a brightness delta are reduced like in the step's own witness.
+func historyParameters(parameters []int) []emulated.Element[innerScalar] {
+	elements := make([]emulated.Element[innerScalar], len(parameters))
+	for i, parameter := range parameters {
+		elements[i] = emulated.ValueOf[innerScalar](new(big.Int).Mod(big.NewInt(int64(parameter)), ecc.BLS12_377.ScalarField()))
+	}
+	return elements
+}
@@ -0,0 +1,162 @@
+package circuits
+
+import (
+	"testing"
+
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+// A circuit with the public inputs of a transformation that proves nothing about them,
+// the kind a forger would set up on their own.
+type forgedCircuit struct {
+	PublicInputs
+}
+
+func (circuit *forgedCircuit) Define(api frontend.API) error {
+	return nil
+}
+
+// The history of a picture, over BLS12-377: its brightness is changed by a parameter, then an identity step
+// keeps the brightened picture.
+type testHistory struct {
+	steps    []Proof
+	captured image.Image // The picture signed by the camera
+	img      image.Image // The picture output by the last step
+	unlinked Proof       // An identity proof of the captured picture, which skips the brightness step
+}
+
+func newTestHistory(assert *test.Assert, params PublicParams, delta int) testHistory {
+	prover, err := NewProver(params)
+	assert.NoError(err)
+	sk, err := NewSecretKey(params.Curve)
+	assert.NoError(err)
+
+	captured, err := image.NewImageOfSize("random", 2, 2)
+	assert.NoError(err)
+	capture, err := NewCaptureProof(captured, sk, params.Curve)
+	assert.NoError(err)
+
+	// Random pictures are gray, the channels saturate at 0
+	bright := image.Image{Pixels: make([]image.Pixel, len(captured.Pixels)), Metadata: captured.Metadata.Clone()}
+	bright.Metadata.History = append(bright.Metadata.History, "brightness")
+	for idx, pixel := range captured.Pixels {
+		level := uint8(max(int(pixel.R)+delta, 0))
+		bright.Pixels[idx] = image.Pixel{R: level, G: level, B: level}
+	}
+
+	publicInputs, err := NewTransformationInputs(params.Curve, "brightness", capture, captured, bright)
+	assert.NoError(err)
+	frImage, err := captured.ToFrImage(params.Curve, prover.MaxImageSize())
+	assert.NoError(err)
+	circuit := BrightnessCircuit{PublicInputs: publicInputs, FrImage: frImage, Params: FrBrightnessT{Delta: delta}}
+	brightness, err := prover.Prove("brightness", &circuit, captured, capture, delta)
+	assert.NoError(err)
+
+	return testHistory{
+		steps:    []Proof{brightness, proveIdentity(assert, prover, bright, brightness)},
+		captured: captured,
+		img:      bright,
+		unlinked: proveIdentity(assert, prover, captured, capture),
+	}
+}
+
+// Return the BW6-761 circuit of a brightness step followed by an identity step.
+func newTestRecursiveCircuit(assert *test.Assert, params PublicParams) RecursiveCircuit {
+	brightness, identity := params.Keys["brightness"], params.Keys["identity"]
+	circuit, err := NewRecursiveCircuit(
+		[]constraint.ConstraintSystem{brightness.Compiled, identity.Compiled},
+		[]groth16.VerifyingKey{brightness.VeriKey, identity.VeriKey},
+	)
+	assert.NoError(err)
+	return circuit
+}
+
+func TestRecursiveCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := Setup(4, ecc.BLS12_377.ScalarField(), "identity", "brightness")
+	assert.NoError(err)
+	history := newTestHistory(assert, params, -5)
+	other := newTestHistory(assert, params, -5)
+	circuit := newTestRecursiveCircuit(assert, params)
+
+	// The history of a brightness step followed by an identity step is solved
+	assignment, err := newRecursiveAssignment(history.steps, history.img)
+	assert.NoError(err)
+	assert.NoError(test.IsSolved(&circuit, &assignment, ecc.BW6_761.ScalarField()))
+
+	// Not with the parameter of another brightness step
+	tampered, err := newRecursiveAssignment(history.steps, history.img)
+	assert.NoError(err)
+	tampered.Parameters = historyParameters([]int{-4})
+	assert.Error(test.IsSolved(&circuit, &tampered, ecc.BW6_761.ScalarField()))
+
+	// Nor with the inner proof of another picture
+	steps := []Proof{history.steps[0], history.steps[1]}
+	steps[1].PCD_Proof = other.steps[1].PCD_Proof
+	tampered, err = newRecursiveAssignment(steps, history.img)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(&circuit, &tampered, ecc.BW6_761.ScalarField()))
+
+	// Nor when the second step, although signed by the same camera, does not transform the output of the first
+	unlinked, err := newRecursiveAssignment([]Proof{history.steps[0], history.unlinked}, history.captured)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(&circuit, &unlinked, ecc.BW6_761.ScalarField()))
+}
+
+func TestHistoryProof(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := Setup(4, ecc.BLS12_377.ScalarField(), "identity", "brightness")
+	assert.NoError(err)
+	history := newTestHistory(assert, params, -5)
+
+	stepVKs := []groth16.VerifyingKey{params.Keys["brightness"].VeriKey, params.Keys["identity"].VeriKey}
+	keys, err := RecursiveSetup(4, []string{"brightness", "identity"}, stepVKs)
+	assert.NoError(err)
+	assert.Equal("history(brightness,identity)", keys.Type())
+
+	// A history must follow the transformation types of its keys
+	_, err = ProveHistory(keys, []Proof{history.steps[1], history.steps[0]}, history.img)
+	assert.Error(err)
+
+	proof, err := ProveHistory(keys, history.steps, history.img)
+	assert.NoError(err)
+	assert.Equal([]int{-5}, proof.Parameters)
+
+	// The history verifies with the trusted recursive key
+	vks := VerifyingKeys{keys.Type(): keys.VeriKey}
+	valid, err := Verifier(proof, history.img, vks)
+	assert.NoError(err)
+	assert.True(valid)
+
+	// But not against another image
+	_, err = Verifier(proof, history.captured, vks)
+	assert.Error(err)
+
+	// Nor with the parameter of another brightness step
+	tampered := proof
+	tampered.Parameters = []int{-4}
+	_, err = Verifier(tampered, history.img, vks)
+	assert.Error(err)
+
+	// Nor by a verifier who does not trust this history
+	_, err = Verifier(proof, history.img, params.VerifyingKeys())
+	assert.Error(err)
+
+	// Nor with a recursive key of a circuit the verifier does not trust
+	foreign, err := frontend.Compile(ecc.BW6_761.ScalarField(), r1cs.NewBuilder, &forgedCircuit{})
+	assert.NoError(err)
+	_, foreignVK, err := groth16.Setup(foreign)
+	assert.NoError(err)
+	proof.VK.VeriKey = foreignVK
+	_, err = Verifier(proof, history.img, vks)
+	assert.Error(err)
+}
@@ -0,0 +1,66 @@
+package circuits
+
+import (
+	"src/image"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// This circuit proves that the output image is the input image turned clockwise by Quarters
+// quarter turns, from 1 to 3. The number of quarter turns is a public parameter, it selects
+// which of the source positions below every pixel of the rotated image comes from.
+type RotateCircuit struct {
+	PublicInputs
+	FrImage image.FrImage
+	Params  FrRotateT
+}
+
+func (circuit *RotateCircuit) Define(api frontend.API) error {
+	if err := circuit.AssertSignedInput(api, RotateCode, circuit.FrImage); err != nil {
+		return err
+	}
+
+	// Check that the rotation turns the image
+	circuit.CheckParams(api)
+
+	rotatedImage, err := circuit.Transform(api)
+	if err != nil {
+		return err
+	}
+
+	// Check that the rotated image is the one committed to by the public output digest
+	return circuit.AssertOutput(api, rotatedImage)
+}
+
+func (circuit *RotateCircuit) CheckParams(api frontend.API) {
+	// Check that Quarters is not 0, a whole turn is not a rotation.
+	// Transform decomposes it in 2 bits, so Quarters <= 3.
+	api.AssertIsDifferent(circuit.Params.Quarters, 0)
+}
+
+func (circuit *RotateCircuit) Transform(api frontend.API) (image.FrImage, error) {
+	width, height := circuit.FrImage.Width, circuit.FrImage.Height
+	lastRow, lastCol := api.Sub(height, 1), api.Sub(width, 1)
+	quarters := api.ToBinary(circuit.Params.Quarters, 2)
+
+	// Odd numbers of quarter turns swap the width and the height
+	newImage := image.NewFrImage(len(circuit.FrImage.Pixels))
+	newImage.Width = api.Select(quarters[0], height, width)
+	newImage.Height = api.Select(quarters[0], width, height)
+
+	// The rest of the metadata is carried from the signed image, with the rotation in its history
+	metadata, err := TransformMetadata(api, circuit.FrImage.Metadata, "rotate")
+	if err != nil {
+		return image.FrImage{}, err
+	}
+	newImage.Metadata = metadata
+
+	// The pixel at (row, col) of the rotated image is the pixel at the source of its number of quarter turns
+	newImage.Pixels = GatherPixels(api, circuit.FrImage, newImage.Width, newImage.Height, func(row, col frontend.Variable) (frontend.Variable, frontend.Variable) {
+		srcRow := api.Lookup2(quarters[0], quarters[1], 0, api.Sub(lastRow, col), api.Sub(lastRow, row), col)
+		srcCol := api.Lookup2(quarters[0], quarters[1], 0, row, api.Sub(lastCol, col), api.Sub(lastCol, row))
+		return srcRow, srcCol
+	})
+
+	return newImage, nil
+}
@@ -3,9 +3,21 @@ package circuits
 import "github.com/consensys/gnark/frontend"
 
 type FrCropT struct {
-	N  frontend.Variable
 	X0 frontend.Variable
 	Y0 frontend.Variable
 	X1 frontend.Variable
 	Y1 frontend.Variable
 }
+
+type FrBrightnessT struct {
+	Delta frontend.Variable `gnark:",public"`
+}
+
+type FrContrastT struct {
+	Numerator   frontend.Variable `gnark:",public"`
+	Denominator frontend.Variable `gnark:",public"`
+}
+
+type FrRotateT struct {
+	Quarters frontend.Variable `gnark:",public"`
+}
@@ -0,0 +1,207 @@
+package circuits
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+// The proof of a transformation applied tile by tile to a picture too large for a single circuit.
+// The camera signs the root of the digests of its tiles (see image.TileRoot), every output tile
+// is proven on its own against the signed digest of the tile it comes from, and the verifier
+// aggregates the tile proofs by recomputing the signed root and the grid of the output tiles.
+type TiledProof struct {
+	Transformation string              // The transformation type of every tile proof, empty for a capture
+	Curve          ecc.ID              // The curve of the camera's signature and of every tile proof
+	PublicKey      signature.PublicKey // The camera's public key
+	Signature      []byte              // The camera's signature over the origin root
+	Origin_Root    []byte              // Root of the tiles signed by the camera
+	Input_Columns  []int               // Grid of the signed tiles
+	Input_Rows     []int
+	Input_Digests  [][]byte // Digest of every signed tile, the leaves of the origin root
+	Output_Columns []int    // Grid of the tiles of the transformed image
+	Output_Rows    []int
+	Sources        []int   // For each output tile, the index of the signed tile it comes from
+	Tiles          []Proof // For each output tile, the proof of its transformation, none for a capture
+}
+
+// Return the proof a camera attaches to a picture it captured as tiles:
+// its public key and its signature over the root of the tiles.
+func NewTiledCaptureProof(tiling image.Tiling, secretKey signature.Signer, curve ecc.ID) (TiledProof, error) {
+	digests, err := tiling.Digests(curve)
+	if err != nil {
+		return TiledProof{}, err
+	}
+
+	root, err := image.TileRoot(curve, tiling.Columns, tiling.Rows, digests)
+	if err != nil {
+		return TiledProof{}, err
+	}
+
+	digSig, err := image.SignDigest(root, secretKey, curve)
+	if err != nil {
+		return TiledProof{}, errors.New("COULD NOT SIGN THE CAPTURED TILES")
+	}
+
+	// Every output tile is the signed tile itself
+	sources := make([]int, len(digests))
+	for i := range sources {
+		sources[i] = i
+	}
+
+	return TiledProof{
+		Curve:          curve,
+		PublicKey:      secretKey.Public(),
+		Signature:      digSig,
+		Origin_Root:    root,
+		Input_Columns:  tiling.Columns,
+		Input_Rows:     tiling.Rows,
+		Input_Digests:  digests,
+		Output_Columns: tiling.Columns,
+		Output_Rows:    tiling.Rows,
+		Sources:        sources,
+	}, nil
+}
+
+// Return the incoming proof to transform the signed tile at index source with.
+// The tile proofs carry the camera's signature over the root, and the digest of the tile as input.
+func (proof TiledProof) TileInput(source int) (Proof, error) {
+	if len(proof.Tiles) != 0 {
+		return Proof{}, errors.New("TILES WERE ALREADY TRANSFORMED")
+	}
+	if source < 0 || source >= len(proof.Input_Digests) {
+		return Proof{}, fmt.Errorf("NO SIGNED TILE %d", source)
+	}
+
+	return Proof{
+		Signature:     proof.Signature,
+		Origin_Digest: proof.Origin_Root,
+		Input_Digest:  proof.Input_Digests[source],
+		VK:            VK{PublicKey: proof.PublicKey},
+	}, nil
+}
+
+// Verify a tiled proof against the image the verifier is looking at:
+//   - the input grid and digests are the ones of the origin root, signed by one of the trusted camera keys,
+//   - the output tiles form a block of the signed grid, whose inner columns and rows are kept whole,
+//   - every tile of img is the signed tile it comes from, or each tile proof verifies, with the trusted
+//     verifying key of its transformation type in vks, against the signed tile it comes from and the tile of img it outputs.
+//
+// The tile proofs do not relate the transformations of different tiles, so only transformations
+// that keep every pixel in its tile, such as crop and identity, can be proven tile by tile.
+func VerifyTiled(proof TiledProof, img image.Image, vks VerifyingKeys, trustedKeys []signature.PublicKey) (bool, error) {
+	if !isTrusted(proof.PublicKey, trustedKeys) {
+		return false, errors.New("INVALID TILED PROOF: THE CAMERA KEY IS NOT TRUSTED")
+	}
+
+	// The grid and the input digests are the ones of the signed root
+	root, err := image.TileRoot(proof.Curve, proof.Input_Columns, proof.Input_Rows, proof.Input_Digests)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(root, proof.Origin_Root) {
+		return false, errors.New("INVALID TILED PROOF: THE TILE DIGESTS ARE NOT THE SIGNED ONES")
+	}
+
+	hFunc, err := image.NewMiMC(proof.Curve)
+	if err != nil {
+		return false, err
+	}
+	valid, err := proof.PublicKey.Verify(proof.Signature, proof.Origin_Root, hFunc)
+	if err != nil || !valid {
+		return false, errors.New("INVALID TILED PROOF: THE CAMERA DID NOT SIGN THE TILES")
+	}
+
+	if err := checkTileGrid(proof); err != nil {
+		return false, fmt.Errorf("INVALID TILED PROOF: %w", err)
+	}
+
+	// Split the image the verifier is looking at in the output tiles
+	tiling, err := img.TileGrid(proof.Output_Columns, proof.Output_Rows)
+	if err != nil {
+		return false, err
+	}
+	digests, err := tiling.Digests(proof.Curve)
+	if err != nil {
+		return false, err
+	}
+
+	// Without transformation, the image is made of the signed tiles
+	if len(proof.Tiles) == 0 {
+		for i, digest := range digests {
+			if !bytes.Equal(digest, proof.Input_Digests[proof.Sources[i]]) {
+				return false, fmt.Errorf("INVALID TILED PROOF: TILE %d IS NOT THE SIGNED ONE", i)
+			}
+		}
+		return true, nil
+	}
+
+	if len(proof.Tiles) != len(digests) {
+		return false, errors.New("INVALID TILED PROOF: THERE IS ONE PROOF PER OUTPUT TILE")
+	}
+	for i, tileProof := range proof.Tiles {
+		// Every tile proof is about its signed tile, under the camera's signature over the root
+		if tileProof.Transformation != proof.Transformation ||
+			vks[tileProof.Transformation] == nil || vks[tileProof.Transformation].CurveID() != proof.Curve ||
+			tileProof.VK.PublicKey == nil || !tileProof.VK.PublicKey.Equal(proof.PublicKey) ||
+			!bytes.Equal(tileProof.Signature, proof.Signature) ||
+			!bytes.Equal(tileProof.Origin_Digest, proof.Origin_Root) ||
+			!bytes.Equal(tileProof.Input_Digest, proof.Input_Digests[proof.Sources[i]]) {
+			return false, fmt.Errorf("INVALID TILED PROOF: TILE %d IS NOT ABOUT ITS SIGNED TILE", i)
+		}
+
+		if err := verifyTransformation(tileProof, digests[i], vks); err != nil {
+			return false, fmt.Errorf("INVALID TILED PROOF: TILE %d: %w", i, err)
+		}
+	}
+
+	return true, nil
+}
+
+// Check that the output tiles come, row by row, from a block of the signed tiles, and that
+// only the first and last columns and rows of the block are smaller than the signed ones.
+func checkTileGrid(proof TiledProof) error {
+	nbColumns, nbRows := len(proof.Output_Columns), len(proof.Output_Rows)
+	if nbColumns == 0 || nbRows == 0 || len(proof.Sources) != nbColumns*nbRows {
+		return errors.New("INVALID OUTPUT TILE GRID")
+	}
+
+	// The first output tile fixes where the block lies in the signed grid
+	first := proof.Sources[0]
+	if first < 0 || first >= len(proof.Input_Digests) {
+		return errors.New("INVALID OUTPUT TILE GRID")
+	}
+	row0, col0 := first/len(proof.Input_Columns), first%len(proof.Input_Columns)
+	if col0+nbColumns > len(proof.Input_Columns) || row0+nbRows > len(proof.Input_Rows) {
+		return errors.New("OUTPUT TILE GRID IS LARGER THAN THE SIGNED ONE")
+	}
+
+	for i, source := range proof.Sources {
+		if source != (row0+i/nbColumns)*len(proof.Input_Columns)+col0+i%nbColumns {
+			return fmt.Errorf("OUTPUT TILE %d IS OUT OF PLACE", i)
+		}
+	}
+
+	if !fitsTiles(proof.Output_Columns, proof.Input_Columns[col0:col0+nbColumns]) ||
+		!fitsTiles(proof.Output_Rows, proof.Input_Rows[row0:row0+nbRows]) {
+		return errors.New("OUTPUT TILES DO NOT FIT IN THE SIGNED ONES")
+	}
+
+	return nil
+}
+
+// Report whether every output length is at most the signed one, and equal to it between the first and last ones.
+func fitsTiles(output []int, signed []int) bool {
+	for i := range output {
+		if output[i] < 1 || output[i] > signed[i] {
+			return false
+		}
+	}
+
+	return len(output) < 3 || slices.Equal(output[1:len(output)-1], signed[1:len(signed)-1])
+}
@@ -0,0 +1,95 @@
+package circuits
+
+import (
+	"fmt"
+	"src/image"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Codes of the transformation types, every circuit exposes its own code in its public inputs
+// so that a verifier learns which transformation a proof is about.
+const (
+	HistoryCode        = iota // A recursive proof over a whole history of transformations
+	IdentityCode              // IdentityCircuit
+	CropCode                  // CropCircuit
+	GrayscaleCode             // GrayscaleCircuit
+	BrightnessCode            // BrightnessCircuit
+	ContrastCode              // ContrastCircuit
+	RotateCode                // RotateCircuit
+	FlipHorizontalCode        // FlipCircuit mirroring left and right
+	FlipVerticalCode          // FlipCircuit mirroring top and bottom
+	FlipBothCode              // FlipCircuit mirroring both ways
+)
+
+// Return the code of the given transformation type.
+func TransformationCode(transformationType string) (int, error) {
+	switch transformationType {
+	case "history":
+		return HistoryCode, nil
+	case "identity":
+		return IdentityCode, nil
+	case "crop":
+		return CropCode, nil
+	case "grayscale":
+		return GrayscaleCode, nil
+	case "brightness":
+		return BrightnessCode, nil
+	case "contrast":
+		return ContrastCode, nil
+	case "rotate":
+		return RotateCode, nil
+	case "flip-horizontal":
+		return FlipHorizontalCode, nil
+	case "flip-vertical":
+		return FlipVerticalCode, nil
+	case "flip-both":
+		return FlipBothCode, nil
+	default:
+		return 0, fmt.Errorf("UNKNOWN TRANSFORMATION TYPE %q", transformationType)
+	}
+}
+
+// Return the number of public parameters of the given transformation type,
+// which follow its public inputs in the public witness.
+func NbParameters(transformationType string) int {
+	switch transformationType {
+	case "brightness", "rotate":
+		return 1
+	case "contrast":
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Return an empty circuit of the given transformation type over images of at most
+// max_image_size pixels, ready to be compiled.
+func CircuitOf(transformationType string, max_image_size int) (frontend.Circuit, error) {
+	if max_image_size < 1 {
+		return nil, fmt.Errorf("INVALID MAXIMUM IMAGE SIZE %d", max_image_size)
+	}
+
+	switch transformationType {
+	case "identity":
+		return &IdentityCircuit{FrImage: image.NewFrImage(max_image_size)}, nil
+	case "crop":
+		return &CropCircuit{FrImage: image.NewFrImage(max_image_size)}, nil
+	case "grayscale":
+		return &GrayscaleCircuit{FrImage: image.NewFrImage(max_image_size)}, nil
+	case "brightness":
+		return &BrightnessCircuit{FrImage: image.NewFrImage(max_image_size)}, nil
+	case "contrast":
+		return &ContrastCircuit{FrImage: image.NewFrImage(max_image_size)}, nil
+	case "rotate":
+		return &RotateCircuit{FrImage: image.NewFrImage(max_image_size)}, nil
+	case "flip-horizontal":
+		return &FlipCircuit{FrImage: image.NewFrImage(max_image_size), Horizontal: true}, nil
+	case "flip-vertical":
+		return &FlipCircuit{FrImage: image.NewFrImage(max_image_size), Vertical: true}, nil
+	case "flip-both":
+		return &FlipCircuit{FrImage: image.NewFrImage(max_image_size), Horizontal: true, Vertical: true}, nil
+	default:
+		return nil, fmt.Errorf("UNKNOWN TRANSFORMATION TYPE %q", transformationType)
+	}
+}
@@ -1,15 +1,100 @@
 package circuits
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
 )
 
-func Verifier(proof Proof) (bool, error) {
-	// Verify the PCD Proof.
-	err := groth16.Verify(proof.PCD_Proof, proof.VK.VeriKey, proof.Public_Witness)
+// The verifying keys a verifier trusts, by transformation type.
+// They come from the public parameters, never from the proofs being verified.
+type VerifyingKeys map[string]groth16.VerifyingKey
+
+// Return the trusted verifying keys, in no particular order.
+func (vks VerifyingKeys) List() []groth16.VerifyingKey {
+	list := make([]groth16.VerifyingKey, 0, len(vks))
+	for _, vk := range vks {
+		list = append(list, vk)
+	}
+	return list
+}
+
+// Return the trusted verifying key of the transformation the proof claims. A proof carrying
+// another verifying key was not proven with the public parameters, so it is rejected.
+func (vks VerifyingKeys) of(proof Proof) (groth16.VerifyingKey, error) {
+	vk, ok := vks[proof.Transformation]
+	if !ok || vk == nil {
+		return nil, fmt.Errorf("NO TRUSTED VERIFYING KEY FOR TRANSFORMATION TYPE %q", proof.Transformation)
+	}
+
+	if proof.VK.VeriKey != nil {
+		trusted, err := VKFingerprint(vk)
+		if err != nil {
+			return nil, err
+		}
+		embedded, err := VKFingerprint(proof.VK.VeriKey)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(trusted, embedded) {
+			return nil, errors.New("INVALID PROOF: IT CARRIES AN UNTRUSTED VERIFYING KEY")
+		}
+	}
+
+	return vk, nil
+}
+
+// Verify the PCD proof against the image the verifier is looking at, with the verifier's own
+// verifying key for the transformation the proof claims.
+// The public witness is rebuilt from img, the camera's public key, its signature
+// and the signed origin digest, rather than trusting the Public_Witness supplied by the prover.
+// A single proof must transform the signed image itself, and BW6-761 proofs are
+// verified as the recursive proof of a whole history.
+func Verifier(proof Proof, img image.Image, vks VerifyingKeys) (bool, error) {
+	vk, err := vks.of(proof)
+	if err != nil {
+		return false, err
+	}
+
+	curve := vk.CurveID()
+	if curve == ecc.BW6_761 {
+		return verifyHistory(proof, img, vk)
+	}
+
+	if !bytes.Equal(proof.Input_Digest, proof.Origin_Digest) {
+		return false, errors.New("INVALID PROOF: IT DOES NOT TRANSFORM THE SIGNED IMAGE")
+	}
+
+	outputDigest, err := img.Digest(curve)
+	if err != nil {
+		return false, err
+	}
+
+	err = verifyTransformation(proof, outputDigest, vks)
 	if err != nil {
 		return false, err
 	}
 
 	return true, err
 }
+
+// Verify a single transformation proof, given the digest of the image it output.
+func verifyTransformation(proof Proof, outputDigest []byte, vks VerifyingKeys) error {
+	vk, err := vks.of(proof)
+	if err != nil {
+		return err
+	}
+	curve := vk.CurveID()
+
+	publicWitness, err := NewPublicWitness(curve, proof.Transformation, proof.VK.PublicKey, proof.Signature, proof.Origin_Digest, proof.Input_Digest, outputDigest, proof.Parameters...)
+	if err != nil {
+		return err
+	}
+
+	// Verify the PCD Proof.
+	return groth16.Verify(proof.PCD_Proof, vk, publicWitness, VerifierOptions(curve)...)
+}
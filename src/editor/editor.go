@@ -3,24 +3,25 @@ package editor
 import (
 	"fmt"
 	"src/circuits"
-	"src/image"
-
-	"github.com/consensys/gnark-crypto/ecc"
 )
 
+// An editor proves transformations of signed pictures, it holds no signing key.
 type Editor struct {
-	IdKeys   circuits.Keys
-	CropKeys circuits.Keys
+	Params circuits.PublicParams
+	Prover *circuits.Prover
 }
 
-func NewEditor() (Editor, error) {
-	fmt.Println("[Camera] Generating new camera")
-	// 1. Generate keys
-	idKeys, cropKey, err := circuits.Generator(image.N, ecc.BN254.ScalarField()) // Generate a secret key for signing
+func NewEditor(params circuits.PublicParams) (Editor, error) {
+	fmt.Println("[Editor] Generating new editor")
+	if params.Keys == nil {
+		return Editor{}, fmt.Errorf("EDITOR NEEDS PUBLIC PARAMETERS")
+	}
+
+	prover, err := circuits.NewProver(params)
 	if err != nil {
 		return Editor{}, err
 	}
 
-	return Editor{IdKeys: idKeys, CropKeys: cropKey}, nil
+	return Editor{Params: params, Prover: prover}, nil
 
 }
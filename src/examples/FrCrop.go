@@ -4,16 +4,20 @@ import (
 	"fmt"
 	"src/circuits"
 	"src/editor"
-	"src/image"
 	"src/secureCamera"
 	"src/transformations"
-
-	"github.com/consensys/gnark-crypto/ecc"
 )
 
 func CropAndProve() {
+	// Generate the public parameters once, they are shared by cameras, editors and verifiers
+	params, err := LoadOrSetup()
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
 	// Create a new camera
-	cam, err := secureCamera.NewCamera()
+	cam, err := secureCamera.NewCamera(params)
 	if err != nil {
 		fmt.Println("Error: ", err)
 	}
@@ -26,22 +30,22 @@ func CropAndProve() {
 	}
 
 	// Verify the proof
-	circuits.Verifier(cam.Proofs[0])
+	circuits.Verifier(cam.Proofs[0], cam.Pictures[0], params.VerifyingKeys())
 
 	// Create a new editor
-	editor, err := editor.NewEditor()
+	editor, err := editor.NewEditor(params)
 	if err != nil {
 		fmt.Println("Error: ", err)
 	}
 
 	// Create a new Crop Transformation
 	t := transformations.CropT{
-		N:  image.N,
 		X0: 5,
 		Y0: 5,
 		X1: 5,
 		Y1: 5,
 	}
 
-	t.TransformAndProve(editor.CropKeys.ProvKey, editor.IdKeys.SecKey, cam.Pictures[0], cam.Proofs[0], ecc.BN254.ScalarField())
+	// The editor only needs the camera's proof, not its secret key
+	t.TransformAndProve(editor.Prover, cam.Pictures[0], cam.Proofs[0])
 }
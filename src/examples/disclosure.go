@@ -0,0 +1,45 @@
+package examples
+
+import (
+	"fmt"
+	"src/circuits"
+	"src/image"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+// A source proves when a picture was taken by a trusted camera, without revealing where or by which device.
+func DiscloseCaptureTime() {
+	identity, err := circuits.NewCameraIdentity(ecc.BN254)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	img, err := image.NewImage("random")
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+	img.Metadata.CaptureTime = time.Date(2024, 5, 17, 8, 30, 0, 0, time.UTC)
+	img.Metadata.DeviceID = "secret device"
+	img.Metadata.GPS = &image.GPS{Latitude: 48858370, Longitude: 2294481}
+
+	commitment, err := circuits.CommitMetadata(img, identity.SecKey, ecc.BN254)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	disclosure, err := commitment.Disclose(image.FieldCaptureTime, circuits.DigestLeaf)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	valid, err := circuits.VerifyDisclosure(disclosure, []signature.PublicKey{identity.PublicKey})
+	fmt.Println("Picture taken at", disclosure.Metadata.CaptureTime, "verified:", valid, err)
+	fmt.Println("Disclosed GPS:", disclosure.Metadata.GPS, "device:", disclosure.Metadata.DeviceID)
+}
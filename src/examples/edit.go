@@ -0,0 +1,55 @@
+package examples
+
+import (
+	"fmt"
+	"src/circuits"
+	"src/editor"
+	"src/image"
+	"src/transformations"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Edit a signed picture with the given transformation, such as transformations.GrayscaleT{}
+// or transformations.BrightnessT{Delta: 20}, and verify the proof of the edit.
+func EditAndProve(t transformations.Transformation) {
+	params, err := circuits.Setup(image.N*image.N, ecc.BN254.ScalarField(), t.GetType())
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	identity, err := circuits.NewCameraIdentity(params.Curve)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	img, err := image.NewImage("random")
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	// The camera only signs the picture, the editor proves the edit
+	proof_in, err := circuits.NewCaptureProof(img, identity.SecKey, params.Curve)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	editor, err := editor.NewEditor(params)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	proof, edited, err := t.TransformAndProve(editor.Prover, img, proof_in)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	valid, err := circuits.Verifier(proof, edited, params.VerifyingKeys())
+	fmt.Println("Edit", t.GetType(), "verified:", valid, err)
+}
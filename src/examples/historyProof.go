@@ -0,0 +1,76 @@
+package examples
+
+import (
+	"fmt"
+	"src/circuits"
+	"src/image"
+	"src/transformations"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+// Prove a picture and an identity edit over BLS12-377, then prove the whole
+// history in a single recursive BW6-761 proof.
+func ProveHistoryExample() {
+	// Steps of the history must be proven over BLS12-377
+	field := ecc.BLS12_377.ScalarField()
+	params, err := circuits.Setup(image.N*image.N, field, "identity")
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	camera, err := circuits.NewCameraIdentity(params.Curve)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+	idKeys := params.Keys["identity"]
+
+	prover, err := circuits.NewProver(params)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	img, err := image.NewImage("random")
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	// The camera signs the picture
+	proof, err := circuits.NewCaptureProof(img, camera.SecKey, ecc.BLS12_377)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	// Each step transforms the output of the previous one
+	history := []circuits.Proof{}
+	for range 2 {
+		proof, img, err = transformations.IdentityT{}.TransformAndProve(prover, img, proof)
+		if err != nil {
+			fmt.Println("Error: ", err)
+			return
+		}
+		history = append(history, proof)
+	}
+
+	// Generate the recursive keys for this sequence of transformations
+	keys, err := circuits.RecursiveSetup(image.N*image.N, []string{"identity", "identity"}, []groth16.VerifyingKey{idKeys.VeriKey, idKeys.VeriKey})
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	historyProof, err := circuits.ProveHistory(keys, history, img)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	// The verifier trusts the recursive key of this history
+	fmt.Println(circuits.Verifier(historyProof, img, circuits.VerifyingKeys{keys.Type(): keys.VeriKey}))
+}
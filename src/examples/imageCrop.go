@@ -14,7 +14,7 @@ func CropExample(x0, y0, x1, y1 int) {
 
 	img.PrintImage()
 
-	t := transformations.CropT{N: image.N, X0: x0, Y0: y0, X1: x1, Y1: y1}
+	t := transformations.CropT{X0: x0, Y0: y0, X1: x1, Y1: y1}
 
 	cropped, err := t.Transform(img)
 	if err != nil {
@@ -0,0 +1,68 @@
+package examples
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/circuits"
+	"src/image"
+	"src/secureCamera"
+	"strings"
+)
+
+// Capture a PNG or JPEG file with a camera, then write the proven picture as a PNG file.
+func CaptureFile(path string, t string, output string) {
+	params, err := LoadOrSetup()
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+	defer file.Close()
+
+	// The picture must fit in the circuits of the public parameters
+	var img image.Image
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		img, err = image.DecodePNG(file, params.MaxImageSize)
+	case ".jpg", ".jpeg":
+		img, err = image.DecodeJPEG(file, params.MaxImageSize)
+	default:
+		err = fmt.Errorf("UNSUPPORTED IMAGE FORMAT %q", filepath.Ext(path))
+	}
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	cam, err := secureCamera.NewCamera(params)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	if err := cam.Capture(img, t); err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	valid, err := circuits.Verifier(cam.Proofs[0], cam.Pictures[0], params.VerifyingKeys())
+	fmt.Println("Captured picture verified:", valid, err)
+
+	// PNG is lossless, the written picture keeps the digest the proof is about
+	out, err := os.Create(output)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+	defer out.Close()
+
+	if err := image.EncodePNG(out, cam.Pictures[0]); err != nil {
+		fmt.Println("Error: ", err)
+	}
+}
@@ -0,0 +1,29 @@
+package examples
+
+import (
+	"fmt"
+	"src/circuits"
+	"src/image"
+	"src/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Directory the examples keep their public parameters in.
+const keyStoreDir = "keys"
+
+// Load the public parameters saved by a previous run, or set them up and save them.
+func LoadOrSetup() (circuits.PublicParams, error) {
+	params, err := keystore.Load(keyStoreDir)
+	if err == nil {
+		return params, nil
+	}
+	fmt.Println("[Setup] Generating public parameters: ", err)
+
+	params, err = circuits.Setup(image.N*image.N, ecc.BN254.ScalarField())
+	if err != nil {
+		return circuits.PublicParams{}, err
+	}
+
+	return params, keystore.Save(keyStoreDir, params)
+}
@@ -0,0 +1,48 @@
+package examples
+
+import (
+	"fmt"
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+// A photographer proves a picture was taken in Paris, without revealing where in Paris.
+func ProvePictureInParis() {
+	identity, err := circuits.NewCameraIdentity(ecc.BN254)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	img, err := image.NewImage("random")
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+	img.Metadata.GPS = &image.GPS{Latitude: 48858370, Longitude: 2294481}
+
+	commitment, err := circuits.CommitMetadata(img, identity.SecKey, ecc.BN254)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	keys, err := circuits.SetupLocation(ecc.BN254, 4)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	paris := circuits.BoxArea(48815573, 2224199, 48902145, 2469920)
+	proof, err := circuits.ProveLocation(commitment, paris, keys)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	valid, err := circuits.VerifyLocation(proof, keys, []signature.PublicKey{identity.PublicKey})
+	fmt.Println("Picture taken in", proof.Area, "verified:", valid, err)
+}
@@ -7,12 +7,18 @@ import (
 
 func NewCameraTakePicture(t string) {
 
-	cam, err := secureCamera.NewCamera()
+	params, err := LoadOrSetup()
 	if err != nil {
 		fmt.Println("Error: ", err)
 		return
 	}
-	fmt.Println(cam.IdKeys.SecKey)
+
+	cam, err := secureCamera.NewCamera(params)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+	fmt.Println(cam.Identity.PublicKey)
 
 	// Take an image & generate a proof
 	err = cam.TakePicture("white", t)
@@ -0,0 +1,59 @@
+package examples
+
+import (
+	"fmt"
+	"src/circuits"
+	"src/editor"
+	"src/image"
+	"src/secureCamera"
+	"src/transformations"
+
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+// Crop a picture larger than the circuits, proving the crop tile by tile.
+func TiledCropAndProve(width int, height int, x0, y0, x1, y1 int) {
+	// The circuits of the public parameters only need to fit a tile
+	params, err := LoadOrSetup()
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	cam, err := secureCamera.NewCamera(params)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	// The camera signs the root of the tiles of the picture
+	tiling, proof, err := cam.TakeTiledPicture("random", width, height, image.N)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	editor, err := editor.NewEditor(params)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	// The editor proves the crop of every tile in parallel
+	t := transformations.CropT{X0: x0, Y0: y0, X1: x1, Y1: y1}
+	proof, cropped, err := t.TransformAndProveTiles(editor.Prover, tiling, proof)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	img, err := cropped.Image()
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
+	// The verifier aggregates the tile proofs against the signed root
+	valid, err := circuits.VerifyTiled(proof, img, params.VerifyingKeys(), []signature.PublicKey{cam.Identity.PublicKey})
+	fmt.Println("Tiled crop verified:", valid, err)
+}
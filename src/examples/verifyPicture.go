@@ -7,8 +7,15 @@ import (
 )
 
 func TakeAndVerifyPictures(flag string, t string) {
+	// Generate the public parameters once, they are shared by cameras, editors and verifiers
+	params, err := LoadOrSetup()
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+
 	// Create a new camera
-	cam, err := secureCamera.NewCamera()
+	cam, err := secureCamera.NewCamera(params)
 	if err != nil {
 		fmt.Println("Error: ", err)
 	}
@@ -22,5 +29,5 @@ func TakeAndVerifyPictures(flag string, t string) {
 	// fmt.Print(cam.Pictures[0])
 
 	// Verify the proof
-	circuits.Verifier(cam.Proofs[0])
+	circuits.Verifier(cam.Proofs[0], cam.Pictures[0], params.VerifyingKeys())
 }
@@ -0,0 +1,69 @@
+package image
+
+import (
+	stdimage "image"
+	"image/color"
+)
+
+// Return the image as a standard library RGBA image.
+func (img Image) ToRGBA() (*stdimage.RGBA, error) {
+	width, height, err := img.Dimensions()
+	if err != nil {
+		return nil, err
+	}
+
+	rgba := stdimage.NewRGBA(stdimage.Rect(0, 0, width, height))
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			pixel := img.Pixels[row*width+col]
+			rgba.SetRGBA(col, row, color.RGBA{R: pixel.R, G: pixel.G, B: pixel.B, A: 255})
+		}
+	}
+
+	return rgba, nil
+}
+
+// Return the image of the given RGBA image. The alpha channel is dropped,
+// the width and height are set in the metadata.
+func FromRGBA(rgba *stdimage.RGBA) (Image, error) {
+	bounds := rgba.Bounds()
+	img, err := NewImageOfSize("black", bounds.Dx(), bounds.Dy())
+	if err != nil {
+		return Image{}, err
+	}
+
+	for row := 0; row < bounds.Dy(); row++ {
+		for col := 0; col < bounds.Dx(); col++ {
+			c := rgba.RGBAAt(bounds.Min.X+col, bounds.Min.Y+row)
+			img.Pixels[row*bounds.Dx()+col] = Pixel{R: c.R, G: c.G, B: c.B}
+		}
+	}
+
+	return img, nil
+}
+
+// Return the image of any standard library image, whatever its color model.
+// Colors are converted to 8-bit RGB, and translucent pixels are flattened over a black
+// background: the alpha-premultiplied color is kept and the alpha channel is dropped.
+// Gray images get pixels whose R, G and B are all the gray level.
+func FromStd(src stdimage.Image) (Image, error) {
+	if rgba, ok := src.(*stdimage.RGBA); ok {
+		return FromRGBA(rgba)
+	}
+
+	bounds := src.Bounds()
+	img, err := NewImageOfSize("black", bounds.Dx(), bounds.Dy())
+	if err != nil {
+		return Image{}, err
+	}
+
+	for row := 0; row < bounds.Dy(); row++ {
+		for col := 0; col < bounds.Dx(); col++ {
+			// RGBA returns 16-bit premultiplied values, keep their high byte
+			r, g, b, _ := src.At(bounds.Min.X+col, bounds.Min.Y+row).RGBA()
+			img.Pixels[row*bounds.Dx()+col] = Pixel{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8)}
+		}
+	}
+
+	return img, nil
+}
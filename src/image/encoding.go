@@ -0,0 +1,98 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Version of the binary encoding of images.
+const EncodingVersion = 2
+
+// First bytes of an encoded image.
+var imageMagic = [4]byte{'P', 'G', 'K', 'I'}
+
+// MarshalBinary returns the canonical encoding of the image, all integers being big-endian:
+//   - the magic bytes "PGKI" and the uint16 version,
+//   - the uint32 length of the encoded metadata, see Metadata.MarshalBinary, and the encoded metadata,
+//   - 3 bytes per pixel, R, G and B, row by row.
+//
+// This is not what Digest hashes: Digest hashes the NbMetadataFields field elements of the
+// metadata (see Metadata.FieldElements), then one field element per pixel packed by PackRGB.
+func (img Image) MarshalBinary() ([]byte, error) {
+	if _, _, err := img.Dimensions(); err != nil {
+		return nil, err
+	}
+
+	metadata, err := img.Metadata.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(imageMagic[:])
+	binary.Write(&buf, binary.BigEndian, uint16(EncodingVersion))
+	binary.Write(&buf, binary.BigEndian, uint32(len(metadata)))
+	buf.Write(metadata)
+
+	for _, pixel := range img.Pixels {
+		buf.Write([]byte{pixel.R, pixel.G, pixel.B})
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes the encoding returned by MarshalBinary. Only the canonical
+// encoding is accepted, so that an image has a single encoding.
+func (img *Image) UnmarshalBinary(data []byte) error {
+	reader := bytes.NewReader(data)
+
+	var fileMagic [4]byte
+	if _, err := io.ReadFull(reader, fileMagic[:]); err != nil || fileMagic != imageMagic {
+		return errors.New("NOT AN ENCODED IMAGE")
+	}
+
+	var header struct {
+		Version        uint16
+		MetadataLength uint32
+	}
+	if err := binary.Read(reader, binary.BigEndian, &header); err != nil {
+		return err
+	}
+	if header.Version != EncodingVersion {
+		return fmt.Errorf("UNSUPPORTED IMAGE ENCODING VERSION %d", header.Version)
+	}
+	if int64(header.MetadataLength) > int64(reader.Len()) {
+		return errors.New("TRUNCATED ENCODED IMAGE")
+	}
+
+	encodedMetadata := make([]byte, header.MetadataLength)
+	if _, err := io.ReadFull(reader, encodedMetadata); err != nil {
+		return err
+	}
+
+	var decoded Image
+	if err := decoded.Metadata.UnmarshalBinary(encodedMetadata); err != nil {
+		return err
+	}
+
+	// Exactly the pixels of the metadata's dimensions follow
+	if uint64(decoded.Metadata.Width)*uint64(decoded.Metadata.Height)*3 != uint64(reader.Len()) {
+		return fmt.Errorf("ENCODED IMAGE DOES NOT HAVE %dx%d PIXELS", decoded.Metadata.Width, decoded.Metadata.Height)
+	}
+
+	rgb := make([]byte, reader.Len())
+	if _, err := io.ReadFull(reader, rgb); err != nil {
+		return err
+	}
+
+	decoded.Pixels = make([]Pixel, len(rgb)/3)
+	for idx := range decoded.Pixels {
+		decoded.Pixels[idx] = Pixel{R: rgb[3*idx], G: rgb[3*idx+1], B: rgb[3*idx+2]}
+	}
+
+	*img = decoded
+	return nil
+}
@@ -0,0 +1,56 @@
+package image
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark/test"
+)
+
+func TestEncodingRoundTrip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	img, err := NewImageOfSize("random", 5, 3)
+	assert.NoError(err)
+	img.Metadata.Author = "Ada"
+	img.Metadata.DeviceID = "CAM-0001"
+	img.Metadata.CaptureTime = time.Date(2024, 5, 17, 8, 30, 0, 0, time.UTC)
+	img.Metadata.GPS = &GPS{Latitude: 48856613, Longitude: -2352222}
+	img.Metadata.Exposure = &Exposure{Time: time.Second / 125, ISO: 400, Aperture: 280}
+	img.Metadata.History = []string{"crop", "identity"}
+
+	encoded, err := img.MarshalBinary()
+	assert.NoError(err)
+
+	var decoded Image
+	assert.NoError(decoded.UnmarshalBinary(encoded))
+	assert.Equal(img, decoded)
+
+	// Anything but the canonical encoding is rejected
+	assert.Error(decoded.UnmarshalBinary(append(encoded, 0)))
+	assert.Error(decoded.UnmarshalBinary(encoded[:len(encoded)-1]))
+
+	// And so is invalid metadata
+	img.Metadata.GPS.Latitude = 91000000
+	_, err = img.MarshalBinary()
+	assert.Error(err)
+}
+
+func TestEncodingIsStable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	img := Image{
+		Pixels:   []Pixel{{R: 1, G: 2, B: 3}, {R: 255, G: 0, B: 128}},
+		Metadata: Metadata{Width: 2, Height: 1, Author: "Ada"},
+	}
+
+	encoded, err := img.MarshalBinary()
+	assert.NoError(err)
+
+	// The encoding of this image must never change, bundles written with it must stay readable
+	expected := "50474b49" + "0002" + "0000001b" +
+		"00000002" + "00000001" + "0003" + hex.EncodeToString([]byte("Ada")) + "0000" + "0000000000000000" + "00" + "00" + "0000" +
+		"010203" + "ff0080"
+	assert.Equal(expected, hex.EncodeToString(encoded))
+}
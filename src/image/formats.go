@@ -0,0 +1,79 @@
+package image
+
+import (
+	"bytes"
+	"fmt"
+	stdimage "image"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Quality JPEG files are encoded with by default.
+const JPEGQuality = 95
+
+// Decode a PNG file into an image, see FromStd for the conversion of its colors.
+// Files of more than max_image_size pixels are rejected before their pixels are decoded.
+func DecodePNG(r io.Reader, max_image_size int) (Image, error) {
+	return decode(r, max_image_size, png.DecodeConfig, png.Decode)
+}
+
+// Decode a JPEG file into an image, see FromStd for the conversion of its colors.
+// Files of more than max_image_size pixels are rejected before their pixels are decoded.
+func DecodeJPEG(r io.Reader, max_image_size int) (Image, error) {
+	return decode(r, max_image_size, jpeg.DecodeConfig, jpeg.Decode)
+}
+
+// Encode the image as an opaque 8-bit RGB PNG file. PNG is lossless, the decoded
+// image has the same pixels, and so the same digest, as the encoded one.
+func EncodePNG(w io.Writer, img Image) error {
+	rgba, err := img.ToRGBA()
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, rgba)
+}
+
+// Encode the image as a JPEG file of the given quality, from 1 to 100.
+// JPEG is lossy: the decoded image has different pixels, so its digest no longer
+// matches the signature or proofs of the encoded image.
+func EncodeJPEG(w io.Writer, img Image, quality int) error {
+	if quality < 1 || quality > 100 {
+		return fmt.Errorf("INVALID JPEG QUALITY %d", quality)
+	}
+
+	rgba, err := img.ToRGBA()
+	if err != nil {
+		return err
+	}
+
+	return jpeg.Encode(w, rgba, &jpeg.Options{Quality: quality})
+}
+
+// Decode a file after checking the dimensions in its header.
+func decode(r io.Reader, max_image_size int, decodeConfig func(io.Reader) (stdimage.Config, error), decodeImage func(io.Reader) (stdimage.Image, error)) (Image, error) {
+	// The header is read twice, once for the dimensions and once by the full decoder
+	file, err := io.ReadAll(r)
+	if err != nil {
+		return Image{}, err
+	}
+
+	config, err := decodeConfig(bytes.NewReader(file))
+	if err != nil {
+		return Image{}, err
+	}
+	if config.Width < 1 || config.Height < 1 {
+		return Image{}, fmt.Errorf("INVALID IMAGE DIMENSIONS %dx%d", config.Width, config.Height)
+	}
+	if config.Width > max_image_size || config.Height > max_image_size || config.Width*config.Height > max_image_size {
+		return Image{}, fmt.Errorf("IMAGE OF %dx%d PIXELS EXCEEDS %d PIXELS", config.Width, config.Height, max_image_size)
+	}
+
+	decoded, err := decodeImage(bytes.NewReader(file))
+	if err != nil {
+		return Image{}, err
+	}
+
+	return FromStd(decoded)
+}
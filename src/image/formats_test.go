@@ -0,0 +1,89 @@
+package image
+
+import (
+	"bytes"
+	stdimage "image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/consensys/gnark/test"
+)
+
+func TestPNGRoundTrip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	img, err := NewImageOfSize("random", 5, 3)
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	assert.NoError(EncodePNG(&buf, img))
+
+	// PNG is lossless
+	decoded, err := DecodePNG(bytes.NewReader(buf.Bytes()), 15)
+	assert.NoError(err)
+	width, height, err := decoded.Dimensions()
+	assert.NoError(err)
+	assert.Equal(5, width)
+	assert.Equal(3, height)
+	assert.Equal(img.Pixels, decoded.Pixels)
+
+	// But an image that does not fit is rejected
+	_, err = DecodePNG(bytes.NewReader(buf.Bytes()), 14)
+	assert.Error(err)
+}
+
+func TestFromStdColorModels(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Translucent pixels are flattened over black
+	nrgba := stdimage.NewNRGBA(stdimage.Rect(0, 0, 2, 1))
+	nrgba.SetNRGBA(0, 0, color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+	nrgba.SetNRGBA(1, 0, color.NRGBA{R: 200, G: 100, B: 50, A: 0})
+
+	var buf bytes.Buffer
+	assert.NoError(png.Encode(&buf, nrgba))
+	img, err := DecodePNG(&buf, 2)
+	assert.NoError(err)
+	assert.Equal([]Pixel{{R: 200, G: 100, B: 50}, {}}, img.Pixels)
+
+	// Gray levels are kept on every channel
+	gray := stdimage.NewGray(stdimage.Rect(3, 4, 4, 5))
+	gray.SetGray(3, 4, color.Gray{Y: 77})
+	img, err = FromStd(gray)
+	assert.NoError(err)
+	assert.Equal([]Pixel{{R: 77, G: 77, B: 77}}, img.Pixels)
+}
+
+func TestJPEGRoundTrip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// A smooth gradient, the kind of picture JPEG compresses with little loss
+	img, err := NewImageOfSize("black", 16, 8)
+	assert.NoError(err)
+	for idx := range img.Pixels {
+		row, col := idx/16, idx%16
+		img.Pixels[idx] = Pixel{R: uint8(100 + 4*col), G: uint8(80 + 4*row), B: 128}
+	}
+
+	var buf bytes.Buffer
+	assert.Error(EncodeJPEG(&buf, img, 0))
+	assert.NoError(EncodeJPEG(&buf, img, JPEGQuality))
+
+	// JPEG is lossy, every channel is only close to the encoded one
+	decoded, err := DecodeJPEG(bytes.NewReader(buf.Bytes()), 128)
+	assert.NoError(err)
+	assert.Equal(img.Metadata.Width, decoded.Metadata.Width)
+	assert.Equal(img.Metadata.Height, decoded.Metadata.Height)
+	for idx, pixel := range decoded.Pixels {
+		for _, channels := range [][2]uint8{{pixel.R, img.Pixels[idx].R}, {pixel.G, img.Pixels[idx].G}, {pixel.B, img.Pixels[idx].B}} {
+			assert.LessOrEqual(max(channels[0], channels[1])-min(channels[0], channels[1]), uint8(8), "pixel %d", idx)
+		}
+	}
+
+	// The file does not say when the picture was taken
+	assert.True(decoded.Metadata.CaptureTime.IsZero())
+
+	_, err = DecodeJPEG(bytes.NewReader(buf.Bytes()), 127)
+	assert.Error(err)
+}
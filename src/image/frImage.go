@@ -2,20 +2,51 @@ package image
 
 import "github.com/consensys/gnark/frontend"
 
+// The pixels of an image inside a circuit, followed by black pixels up to the
+// number of pixels the circuit was compiled for.
 type FrImage struct {
-	Pixels [N * N]frontend.Variable // Secret
+	Pixels   []frontend.Variable // Secret
+	Width    frontend.Variable   // Secret
+	Height   frontend.Variable   // Secret
+	Metadata FrMetadata          // Secret
 }
 
-/* Start of Interface functions. */
-
-// This SetPixel function packs the pixel before setting it at the location (x=col, y=row) in the img,
-// where (0,0) is top left corner.
-func (img *FrImage) SetPixel(col int, row int, pixel Pixel) {
-	// Flatten the 2D location into a 1D index.
-	idx := row*N + col
+// The field elements of the metadata inside a circuit, but the width and height
+// which are the ones of the FrImage. See Metadata.FieldElements.
+type FrMetadata struct {
+	Author       frontend.Variable
+	DeviceID     frontend.Variable
+	CaptureTime  frontend.Variable
+	HasGPS       frontend.Variable
+	Latitude     frontend.Variable
+	Longitude    frontend.Variable
+	HasExposure  frontend.Variable
+	ExposureTime frontend.Variable
+	ISO          frontend.Variable
+	Aperture     frontend.Variable
+	History      frontend.Variable
+}
 
-	// Set the packed pixel at the 1D index.
-	img.Pixels[idx] = frontend.Variable(pixel.PackRGB())
+// Return an FrImage of max_image_size pixels, as circuits must be compiled with.
+func NewFrImage(max_image_size int) FrImage {
+	return FrImage{Pixels: make([]frontend.Variable, max_image_size)}
 }
 
-/* End of Interface functions. */
+// Return the field elements of the metadata of the image, in the order of the Field constants.
+func (img FrImage) MetadataElements() []frontend.Variable {
+	elements := make([]frontend.Variable, NbMetadataFields)
+	elements[FieldAuthor] = img.Metadata.Author
+	elements[FieldDeviceID] = img.Metadata.DeviceID
+	elements[FieldCaptureTime] = img.Metadata.CaptureTime
+	elements[FieldWidth] = img.Width
+	elements[FieldHeight] = img.Height
+	elements[FieldHasGPS] = img.Metadata.HasGPS
+	elements[FieldLatitude] = img.Metadata.Latitude
+	elements[FieldLongitude] = img.Metadata.Longitude
+	elements[FieldHasExposure] = img.Metadata.HasExposure
+	elements[FieldExposureTime] = img.Metadata.ExposureTime
+	elements[FieldISO] = img.Metadata.ISO
+	elements[FieldAperture] = img.Metadata.Aperture
+	elements[FieldHistory] = img.Metadata.History
+	return elements
+}
@@ -2,18 +2,19 @@ package image
 
 import (
 	"crypto/rand"
-	"encoding/json"
+	"encoding/binary"
 	"fmt"
+	stdhash "hash"
 	"math/big"
 
-	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark-crypto/signature"
 )
 
 const (
-	// The size of an image in pixels.
-	N = 14 // Remember an image will have N*N pixels
+	// The width and height of the pictures NewImage creates, any other size can be created with NewImageOfSize.
+	N = 14
 )
 
 type Pixel struct {
@@ -22,16 +23,27 @@ type Pixel struct {
 	B uint8
 }
 
+// The pixels are stored row by row, the width and height are set in the metadata.
 type Image struct {
-	Pixels   [N * N]Pixel
-	Metadata map[string]interface{}
+	Pixels   []Pixel
+	Metadata Metadata
 }
 
-// Can create a "white" or "black" or "random" image
+// Can create a "white" or "black" or "random" image of N*N pixels
 func NewImage(flag string) (Image, error) {
+	return NewImageOfSize(flag, N, N)
+}
+
+// Can create a "white" or "black" or "random" image of the given width and height.
+// The capture time is left unknown, only the camera records when a picture was taken.
+func NewImageOfSize(flag string, width int, height int) (Image, error) {
+	if width < 1 || height < 1 {
+		return Image{}, fmt.Errorf("INVALID IMAGE DIMENSIONS %dx%d", width, height)
+	}
+
 	newImage := Image{
-		Pixels:   [N * N]Pixel{},
-		Metadata: make(map[string]interface{}),
+		Pixels:   make([]Pixel, width*height),
+		Metadata: Metadata{Width: width, Height: height},
 	}
 
 	// Create a black and white pixel
@@ -39,57 +51,36 @@ func NewImage(flag string) (Image, error) {
 	whitePixel := Pixel{R: 255, G: 255, B: 255}
 
 	// For each pixel
-	for row := 0; row < N; row++ {
-		for col := 0; col < N; col++ {
-			if flag == "" {
-				return newImage, nil
-			}
-
-			if flag == "black" {
-
-				// Translate the 2D location (x,y) into a 1D index.
-				idx := row*N + col
+	for idx := range newImage.Pixels {
+		if flag == "" {
+			return newImage, nil
+		}
 
-				// Set pixels as black
-				newImage.Pixels[idx] = blackPixel
-			}
+		if flag == "black" {
+			// Set pixels as black
+			newImage.Pixels[idx] = blackPixel
+		}
 
-			if flag == "white" {
-				// Translate the 2D location (x,y) into a 1D index.
-				idx := row*N + col
+		if flag == "white" {
+			// Set pixels as white
+			newImage.Pixels[idx] = whitePixel
+		}
 
-				// Set pixels as black
-				newImage.Pixels[idx] = whitePixel
+		if flag == "random" {
+			// Generate a random number between 0 and 255
+			n, err := rand.Int(rand.Reader, big.NewInt(256))
+			if err != nil {
+				return Image{}, err
 			}
 
-			if flag == "random" {
-				// Generate a random number between 0 and 255
-				n, err := rand.Int(rand.Reader, big.NewInt(256))
-				if err != nil {
-					return Image{}, err
-				}
-
-				// Convert the result to uint8
-				randomUint8 := uint8(n.Int64())
+			// Convert the result to uint8
+			randomUint8 := uint8(n.Int64())
 
-				// Create a random pixel
-				randomPixel := Pixel{R: randomUint8, G: randomUint8, B: randomUint8}
-
-				// Translate the 2D location (x,y) into a 1D index.
-				idx := row*N + col
-
-				// Set pixels as randomPixel
-				newImage.Pixels[idx] = randomPixel
-			}
+			// Set pixels as a random gray pixel
+			newImage.Pixels[idx] = Pixel{R: randomUint8, G: randomUint8, B: randomUint8}
 		}
 	}
 
-	// Set metadata
-	newImage.Metadata["author"] = "John Doe"
-	newImage.Metadata["N"] = N
-	newImage.Metadata["height"] = N
-	newImage.Metadata["width"] = N
-
 	return newImage, nil
 }
 
@@ -99,14 +90,32 @@ func (pixel Pixel) PackRGB() uint32 {
 	return uint32(pixel.R)<<16 | uint32(pixel.G)<<8 | uint32(pixel.B)
 }
 
-// PrintImage outputs the image in a 16x16 grid format.
+// Weights of the red, green and blue channels in the luma of a pixel, the BT.601 weights scaled to 256.
+const (
+	LumaR = 77
+	LumaG = 150
+	LumaB = 29
+)
+
+// Return the luma of the pixel, (LumaR*R + LumaG*G + LumaB*B) / 256 rounded to the nearest integer.
+// The weights add up to 256, so the luma of a gray pixel is its gray level.
+func (pixel Pixel) Luma() uint8 {
+	return uint8((LumaR*uint32(pixel.R) + LumaG*uint32(pixel.G) + LumaB*uint32(pixel.B) + 128) >> 8)
+}
+
+// PrintImage outputs the image as a grid of (R, G, B) pixels.
 func (img *Image) PrintImage() {
+	width, height, err := img.Dimensions()
+	if err != nil {
+		fmt.Println("Error while printing image: " + err.Error())
+		return
+	}
+
 	// For each row
-	for row := 0; row < N; row++ {
-		// Print all indices in the row
-		for col := 0; col < N; col++ {
-			currentIdx := row*N + col
-			pixel := img.Pixels[currentIdx]
+	for row := 0; row < height; row++ {
+		// Print all pixels in the row
+		for col := 0; col < width; col++ {
+			pixel := img.Pixels[row*width+col]
 			// Print pixel in (R, G, B) format
 			fmt.Printf("(%3d, %3d, %3d) ", pixel.R, pixel.G, pixel.B)
 		}
@@ -114,9 +123,9 @@ func (img *Image) PrintImage() {
 	}
 }
 
-// Return the JSON encoded version of an image as bytes.
+// Return the canonical binary encoding of the image, see MarshalBinary.
 func (img Image) ToByte() []byte {
-	encoded_image, err := json.Marshal(img)
+	encoded_image, err := img.MarshalBinary()
 	if err != nil {
 		fmt.Println("Error while encoding image: " + err.Error())
 		return []byte{}
@@ -125,56 +134,144 @@ func (img Image) ToByte() []byte {
 	return encoded_image
 }
 
-// Interprets image bytes as the bytes of a big-endian unsigned integer, sets z to that value, and return z value as a big endian slice.
-// If this step is skipped, you get this error:
-// "runtime error: slice bounds out of range".
-// This step is required to define an image into something that Gnark circuits understand.
-func (img Image) ToBigEndian() []byte {
+// Decode an image from the encoding returned by ToByte.
+func FromByte(encoded_image []byte) (Image, error) {
+	var img Image
+	if err := img.UnmarshalBinary(encoded_image); err != nil {
+		return Image{}, err
+	}
 
-	// Define the picture as a "z value of a field element (fr.element)" that's converted into a big endian
-	img_bytes := img.ToByte() // Encode image into bytes using JSON
+	return img, nil
+}
+
+// Return the width and height stored in the image's metadata.
+func (img Image) Dimensions() (int, int, error) {
+	// Retrieve image's actual width & height from the metadata
+	width, height := img.Metadata.Width, img.Metadata.Height
+
+	// Check that width and height values are valid
+	if width < 1 || height < 1 {
+		return 0, 0, fmt.Errorf("INVALID IMAGE WIDHT/HEIGHT IN METADATA")
+	}
 
-	var msgFr fr.Element // Define a field element
+	// Check that they describe the pixels
+	if len(img.Pixels) != width*height {
+		return 0, 0, fmt.Errorf("IMAGE HAS %d PIXELS, NOT %dx%d", len(img.Pixels), width, height)
+	}
 
-	// (https://pkg.go.dev/github.com/consensys/gnark-crypto@v0.9.1/ecc/bn254/fr#Element.SetBytes)
-	msgFr.SetBytes(img_bytes)                 // Set the image bytes as the z value for the fr.Element
-	big_endian_bytes_Image := msgFr.Marshal() // Convert z value to a big endian slice
+	return width, height, nil
+}
 
-	return big_endian_bytes_Image
+// Return the MiMC hash function over the scalar field of the given curve.
+func NewMiMC(curve ecc.ID) (stdhash.Hash, error) {
+	switch curve {
+	case ecc.BN254:
+		return hash.MIMC_BN254.New(), nil
+	case ecc.BLS12_377:
+		return hash.MIMC_BLS12_377.New(), nil
+	default:
+		return nil, fmt.Errorf("NO MIMC HASH FOR CURVE %s", curve)
+	}
 }
 
-// Return an FrImage that has FrPixels equivalent to RGBPixels in the img.
-func (img Image) ToFrImage() FrImage {
-	// Create a new FrImage
-	frImage := FrImage{}
+// Digest returns the MiMC hash of the image over the scalar field of the given curve.
+// The hash is computed over the field elements of the metadata, which include the width
+// and height, followed by one field element per packed pixel. circuits.ImageDigest computes
+// the exact same value inside a circuit, which is what binds a signature to the pixels and metadata.
+func (img Image) Digest(curve ecc.ID) ([]byte, error) {
+	if _, _, err := img.Dimensions(); err != nil {
+		return nil, err
+	}
 
-	// For each row of length N
-	for row := 0; row < N; row++ {
-		// For each col index in the row
-		for col := 0; col < N; col++ {
+	metadata, err := img.Metadata.FieldElements(curve)
+	if err != nil {
+		return nil, err
+	}
 
-			currentIdx := row*N + col
+	hFunc, err := NewMiMC(curve)
+	if err != nil {
+		return nil, err
+	}
 
-			// Get RGBPixel
-			pixel := img.Pixels[currentIdx]
+	// Each value is written as a big endian field element
+	elem := make([]byte, hFunc.BlockSize())
 
-			// Set RGBPixel as an FrPixel in the newly created FrImage
-			frImage.SetPixel(col, row, pixel)
-		}
+	// The metadata comes first, it fixes the number of pixels that follow
+	for _, element := range metadata {
+		element.FillBytes(elem)
+		hFunc.Write(elem)
+	}
+	clear(elem)
+
+	// Each packed pixel is written as its own field element
+	for idx := range img.Pixels {
+		binary.BigEndian.PutUint64(elem[len(elem)-8:], uint64(img.Pixels[idx].PackRGB()))
+		hFunc.Write(elem)
 	}
 
-	return frImage
+	return hFunc.Sum(nil), nil
 }
 
-func (img Image) Sign(secretKey signature.Signer) []byte {
+// Return an FrImage of max_image_size pixels that has FrPixels equivalent to RGBPixels in the img.
+// The pixels of the img come first, the remaining ones are black. The metadata is set
+// as field elements over the scalar field of the given curve.
+func (img Image) ToFrImage(curve ecc.ID, max_image_size int) (FrImage, error) {
+	// Set the width and height from the metadata, they are part of the image digest
+	width, height, err := img.Dimensions()
+	if err != nil {
+		return FrImage{}, err
+	}
+	if width*height > max_image_size {
+		return FrImage{}, fmt.Errorf("IMAGE OF %dx%d PIXELS DOES NOT FIT IN %d PIXELS", width, height, max_image_size)
+	}
 
-	// 3. Instantiate MIMC BN254 hash function, to be used in signing the image
-	hFunc := hash.MIMC_BN254.New()
+	// Create a new FrImage
+	frImage := NewFrImage(max_image_size)
+	frImage.Width = width
+	frImage.Height = height
+
+	metadata, err := img.Metadata.FieldElements(curve)
+	if err != nil {
+		return FrImage{}, err
+	}
+	frImage.Metadata = FrMetadata{
+		Author:       metadata[FieldAuthor],
+		DeviceID:     metadata[FieldDeviceID],
+		CaptureTime:  metadata[FieldCaptureTime],
+		HasGPS:       metadata[FieldHasGPS],
+		Latitude:     metadata[FieldLatitude],
+		Longitude:    metadata[FieldLongitude],
+		HasExposure:  metadata[FieldHasExposure],
+		ExposureTime: metadata[FieldExposureTime],
+		ISO:          metadata[FieldISO],
+		Aperture:     metadata[FieldAperture],
+		History:      metadata[FieldHistory],
+	}
+
+	// Set each RGBPixel as an FrPixel in the newly created FrImage
+	for idx := range frImage.Pixels {
+		if idx < len(img.Pixels) {
+			frImage.Pixels[idx] = img.Pixels[idx].PackRGB()
+		} else {
+			frImage.Pixels[idx] = 0
+		}
+	}
+
+	return frImage, nil
+}
+
+// Sign the image's digest using the given secret key, which must be a key on the given curve.
+func (img Image) Sign(secretKey signature.Signer, curve ecc.ID) []byte {
 
-	img_big_endian := img.ToBigEndian()
+	// 1. Compute the digest of the pixels and metadata, this is what the circuits re-compute
+	digest, err := img.Digest(curve)
+	if err != nil {
+		fmt.Println("Error while hashing image: " + err.Error())
+		return []byte{}
+	}
 
-	// 4. Sign the image (must first turn the image into a Big Endian)
-	signature, err := secretKey.Sign(img_big_endian, hFunc)
+	// 2. Sign the image digest with the MiMC hash function
+	signature, err := SignDigest(digest, secretKey, curve)
 	if err != nil {
 		fmt.Println("Error while signing image: " + err.Error())
 	}
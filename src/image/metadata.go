@@ -0,0 +1,421 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Maximum length in bytes of the author, device ID and transformation types of the metadata.
+const MaxStringLength = 255
+
+// The metadata a camera records with a picture.
+type Metadata struct {
+	Author      string
+	DeviceID    string
+	CaptureTime time.Time // Recorded to the second, the zero time when unknown
+	Width       int
+	Height      int
+	GPS         *GPS      // nil when the camera had no location
+	Exposure    *Exposure // nil when unknown
+	History     []string  // The types of the transformations applied since the capture, oldest first
+}
+
+// A location, in millionths of a degree.
+type GPS struct {
+	Latitude  int64 // From -90000000 to 90000000
+	Longitude int64 // From -180000000 to 180000000
+}
+
+type Exposure struct {
+	Time     time.Duration // The shutter speed
+	ISO      int
+	Aperture int // The f-number times 100, 280 for f/2.8
+}
+
+// Index of each metadata field in the field elements of the metadata.
+const (
+	FieldAuthor = iota
+	FieldDeviceID
+	FieldCaptureTime
+	FieldWidth
+	FieldHeight
+	FieldHasGPS
+	FieldLatitude
+	FieldLongitude
+	FieldHasExposure
+	FieldExposureTime
+	FieldISO
+	FieldAperture
+	FieldHistory
+	NbMetadataFields
+)
+
+// Offsets added to the latitude and longitude so that their field elements are not negative.
+const (
+	LatitudeOffset  = 90000000
+	LongitudeOffset = 180000000
+)
+
+// Check that every field of the metadata has a value its encodings can represent.
+func (m Metadata) Validate() error {
+	if m.Width < 1 || m.Height < 1 || m.Width > math.MaxUint32 || m.Height > math.MaxUint32 {
+		return fmt.Errorf("INVALID IMAGE DIMENSIONS %dx%d", m.Width, m.Height)
+	}
+
+	for _, s := range append([]string{m.Author, m.DeviceID}, m.History...) {
+		if len(s) > MaxStringLength || !utf8.ValidString(s) {
+			return fmt.Errorf("INVALID METADATA STRING %.16q", s)
+		}
+	}
+	if slices.Contains(m.History, "") {
+		return errors.New("EMPTY TRANSFORMATION TYPE IN HISTORY")
+	}
+
+	if !m.CaptureTime.IsZero() && (m.CaptureTime.Unix() <= 0 || m.CaptureTime.Nanosecond() != 0) {
+		return fmt.Errorf("INVALID CAPTURE TIME %s", m.CaptureTime)
+	}
+
+	if m.GPS != nil {
+		if m.GPS.Latitude < -LatitudeOffset || m.GPS.Latitude > LatitudeOffset ||
+			m.GPS.Longitude < -LongitudeOffset || m.GPS.Longitude > LongitudeOffset {
+			return fmt.Errorf("INVALID GPS LOCATION %d, %d", m.GPS.Latitude, m.GPS.Longitude)
+		}
+	}
+
+	if m.Exposure != nil {
+		if m.Exposure.Time <= 0 || m.Exposure.ISO <= 0 || m.Exposure.ISO > math.MaxInt32 ||
+			m.Exposure.Aperture <= 0 || m.Exposure.Aperture > math.MaxInt32 {
+			return errors.New("INVALID EXPOSURE")
+		}
+	}
+
+	return nil
+}
+
+// Return a copy of the metadata that shares nothing with it.
+func (m Metadata) Clone() Metadata {
+	clone := m
+	if m.GPS != nil {
+		gps := *m.GPS
+		clone.GPS = &gps
+	}
+	if m.Exposure != nil {
+		exposure := *m.Exposure
+		clone.Exposure = &exposure
+	}
+	clone.History = slices.Clone(m.History)
+
+	return clone
+}
+
+// MarshalBinary returns the canonical encoding of the metadata, all integers being big-endian:
+// the uint32 width and height, the author and device ID, the int64 Unix time of the capture,
+// 0 when unknown, a 0 or 1 byte telling whether the GPS location follows as two int64,
+// a 0 or 1 byte telling whether the exposure follows as three int64, the nanoseconds of
+// the shutter speed, the ISO and the aperture, then the uint16 number of transformation types
+// of the history and each of them. Every string is prefixed by its uint16 length.
+func (m Metadata) MarshalBinary() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(m.Width))
+	binary.Write(&buf, binary.BigEndian, uint32(m.Height))
+	writeString(&buf, m.Author)
+	writeString(&buf, m.DeviceID)
+	binary.Write(&buf, binary.BigEndian, m.captureTime())
+
+	if m.GPS == nil {
+		buf.WriteByte(0)
+	} else {
+		buf.WriteByte(1)
+		binary.Write(&buf, binary.BigEndian, []int64{m.GPS.Latitude, m.GPS.Longitude})
+	}
+
+	if m.Exposure == nil {
+		buf.WriteByte(0)
+	} else {
+		buf.WriteByte(1)
+		binary.Write(&buf, binary.BigEndian, []int64{int64(m.Exposure.Time), int64(m.Exposure.ISO), int64(m.Exposure.Aperture)})
+	}
+
+	binary.Write(&buf, binary.BigEndian, uint16(len(m.History)))
+	for _, transformationType := range m.History {
+		writeString(&buf, transformationType)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes the encoding returned by MarshalBinary.
+func (m *Metadata) UnmarshalBinary(data []byte) error {
+	reader := bytes.NewReader(data)
+	decoded, err := readMetadata(reader)
+	if err != nil {
+		return err
+	}
+	if reader.Len() != 0 {
+		return errors.New("TRAILING BYTES IN ENCODED METADATA")
+	}
+
+	*m = decoded
+	return nil
+}
+
+// Read metadata encoded by MarshalBinary, and check it is valid.
+func readMetadata(reader *bytes.Reader) (Metadata, error) {
+	var m Metadata
+
+	var dimensions [2]uint32
+	if err := binary.Read(reader, binary.BigEndian, &dimensions); err != nil {
+		return Metadata{}, err
+	}
+	m.Width, m.Height = int(dimensions[0]), int(dimensions[1])
+
+	var err error
+	if m.Author, err = readString(reader); err != nil {
+		return Metadata{}, err
+	}
+	if m.DeviceID, err = readString(reader); err != nil {
+		return Metadata{}, err
+	}
+
+	var captureTime int64
+	if err := binary.Read(reader, binary.BigEndian, &captureTime); err != nil {
+		return Metadata{}, err
+	}
+	if captureTime != 0 {
+		m.CaptureTime = time.Unix(captureTime, 0).UTC()
+	}
+
+	if present, err := readFlag(reader); err != nil {
+		return Metadata{}, err
+	} else if present {
+		var location [2]int64
+		if err := binary.Read(reader, binary.BigEndian, &location); err != nil {
+			return Metadata{}, err
+		}
+		m.GPS = &GPS{Latitude: location[0], Longitude: location[1]}
+	}
+
+	if present, err := readFlag(reader); err != nil {
+		return Metadata{}, err
+	} else if present {
+		var exposure [3]int64
+		if err := binary.Read(reader, binary.BigEndian, &exposure); err != nil {
+			return Metadata{}, err
+		}
+		if exposure[1] > math.MaxInt32 || exposure[2] > math.MaxInt32 {
+			return Metadata{}, errors.New("INVALID EXPOSURE")
+		}
+		m.Exposure = &Exposure{Time: time.Duration(exposure[0]), ISO: int(exposure[1]), Aperture: int(exposure[2])}
+	}
+
+	var nbTransformations uint16
+	if err := binary.Read(reader, binary.BigEndian, &nbTransformations); err != nil {
+		return Metadata{}, err
+	}
+	for range nbTransformations {
+		transformationType, err := readString(reader)
+		if err != nil {
+			return Metadata{}, err
+		}
+		m.History = append(m.History, transformationType)
+	}
+
+	return m, m.Validate()
+}
+
+// Return the metadata as field elements, in the order of the Field constants:
+//   - the author, device ID and each transformation type are hashed with StringElement,
+//   - the capture time is its Unix time, 0 when unknown,
+//   - the GPS location and exposure are preceded by 1 when known, and are all 0 otherwise,
+//     the latitude and longitude are offset by LatitudeOffset and LongitudeOffset,
+//   - the history is the chain of MiMC hashes starting from 0 and hashing, for every
+//     transformation type, the previous hash with the type's StringElement. See HistoryElement.
+func (m Metadata) FieldElements(curve ecc.ID) ([]*big.Int, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	elements := make([]*big.Int, NbMetadataFields)
+	for field := range elements {
+		element, err := m.FieldElement(curve, field)
+		if err != nil {
+			return nil, err
+		}
+		elements[field] = element
+	}
+
+	return elements, nil
+}
+
+// Return the field element of a single field of the metadata, see FieldElements.
+// The other fields are ignored, they do not even need to be valid.
+func (m Metadata) FieldElement(curve ecc.ID, field int) (*big.Int, error) {
+	switch field {
+	case FieldAuthor:
+		return StringElement(curve, m.Author)
+	case FieldDeviceID:
+		return StringElement(curve, m.DeviceID)
+	case FieldCaptureTime:
+		return big.NewInt(m.captureTime()), nil
+	case FieldWidth:
+		return big.NewInt(int64(m.Width)), nil
+	case FieldHeight:
+		return big.NewInt(int64(m.Height)), nil
+	case FieldHasGPS:
+		return flagElement(m.GPS != nil), nil
+	case FieldLatitude:
+		if m.GPS == nil {
+			return new(big.Int), nil
+		}
+		return big.NewInt(m.GPS.Latitude + LatitudeOffset), nil
+	case FieldLongitude:
+		if m.GPS == nil {
+			return new(big.Int), nil
+		}
+		return big.NewInt(m.GPS.Longitude + LongitudeOffset), nil
+	case FieldHasExposure:
+		return flagElement(m.Exposure != nil), nil
+	case FieldExposureTime, FieldISO, FieldAperture:
+		if m.Exposure == nil {
+			return new(big.Int), nil
+		}
+		return big.NewInt([]int64{int64(m.Exposure.Time), int64(m.Exposure.ISO), int64(m.Exposure.Aperture)}[field-FieldExposureTime]), nil
+	case FieldHistory:
+		history := new(big.Int)
+		for _, transformationType := range m.History {
+			var err error
+			if history, err = HistoryElement(curve, history, transformationType); err != nil {
+				return nil, err
+			}
+		}
+		return history, nil
+	default:
+		return nil, fmt.Errorf("NO METADATA FIELD %d", field)
+	}
+}
+
+// Digest returns the MiMC hash of the field elements of the metadata.
+func (m Metadata) Digest(curve ecc.ID) ([]byte, error) {
+	elements, err := m.FieldElements(curve)
+	if err != nil {
+		return nil, err
+	}
+
+	return hashElements(curve, elements...)
+}
+
+// StringElement returns the MiMC hash of the length of s followed by s cut in big-endian
+// chunks of 31 bytes, small enough to be field elements of any supported curve.
+func StringElement(curve ecc.ID, s string) (*big.Int, error) {
+	elements := []*big.Int{big.NewInt(int64(len(s)))}
+	for start := 0; start < len(s); start += 31 {
+		elements = append(elements, new(big.Int).SetBytes([]byte(s[start:min(start+31, len(s))])))
+	}
+
+	digest, err := hashElements(curve, elements...)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(digest), nil
+}
+
+// HistoryElement returns the history element of a history whose element is previous,
+// after the transformation of the given type.
+func HistoryElement(curve ecc.ID, previous *big.Int, transformationType string) (*big.Int, error) {
+	element, err := StringElement(curve, transformationType)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := hashElements(curve, previous, element)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(digest), nil
+}
+
+// Return the MiMC hash of the given field elements.
+func hashElements(curve ecc.ID, elements ...*big.Int) ([]byte, error) {
+	hFunc, err := NewMiMC(curve)
+	if err != nil {
+		return nil, err
+	}
+
+	elem := make([]byte, hFunc.BlockSize())
+	for _, element := range elements {
+		element.FillBytes(elem)
+		if _, err := hFunc.Write(elem); err != nil {
+			return nil, err
+		}
+	}
+
+	return hFunc.Sum(nil), nil
+}
+
+func flagElement(flag bool) *big.Int {
+	if flag {
+		return big.NewInt(1)
+	}
+	return new(big.Int)
+}
+
+// Return the Unix time of the capture, 0 when unknown.
+func (m Metadata) captureTime() int64 {
+	if m.CaptureTime.IsZero() {
+		return 0
+	}
+	return m.CaptureTime.Unix()
+}
+
+// Write a string prefixed by its uint16 length.
+func writeString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+// Read a string written by writeString.
+func readString(reader *bytes.Reader) (string, error) {
+	var length uint16
+	if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+		return "", err
+	}
+	if int(length) > reader.Len() {
+		return "", errors.New("TRUNCATED ENCODING")
+	}
+
+	s := make([]byte, length)
+	if _, err := io.ReadFull(reader, s); err != nil {
+		return "", err
+	}
+
+	return string(s), nil
+}
+
+// Read a 0 or 1 byte.
+func readFlag(reader *bytes.Reader) (bool, error) {
+	flag, err := reader.ReadByte()
+	if err != nil {
+		return false, err
+	}
+	if flag > 1 {
+		return false, fmt.Errorf("INVALID FLAG %d", flag)
+	}
+
+	return flag == 1, nil
+}
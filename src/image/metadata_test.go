@@ -0,0 +1,49 @@
+package image
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+)
+
+func TestMetadataFieldElements(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	metadata := Metadata{
+		Author:      "Ada",
+		CaptureTime: time.Unix(1715934600, 0),
+		Width:       4,
+		Height:      3,
+		GPS:         &GPS{Latitude: -33868820, Longitude: 151209290},
+		History:     []string{"crop"},
+	}
+
+	elements, err := metadata.FieldElements(ecc.BN254)
+	assert.NoError(err)
+	assert.Equal(NbMetadataFields, len(elements))
+	assert.Equal(int64(1715934600), elements[FieldCaptureTime].Int64())
+	assert.Equal(int64(4), elements[FieldWidth].Int64())
+	assert.Equal(int64(1), elements[FieldHasGPS].Int64())
+	assert.Equal(int64(-33868820+LatitudeOffset), elements[FieldLatitude].Int64())
+	assert.Equal(int64(0), elements[FieldHasExposure].Int64())
+
+	// The history is a chain of hashes
+	history, err := HistoryElement(ecc.BN254, big.NewInt(0), "crop")
+	assert.NoError(err)
+	assert.Equal(history, elements[FieldHistory])
+
+	// Every field is part of the digest
+	digest, err := metadata.Digest(ecc.BN254)
+	assert.NoError(err)
+	metadata.Author = "Bob"
+	other, err := metadata.Digest(ecc.BN254)
+	assert.NoError(err)
+	assert.NotEqual(digest, other)
+
+	// Times are recorded to the second
+	metadata.CaptureTime = metadata.CaptureTime.Add(time.Millisecond)
+	assert.Error(metadata.Validate())
+}
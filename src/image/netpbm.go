@@ -0,0 +1,204 @@
+package image
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Netpbm formats: P2 and P5 are gray maps (PGM), P3 and P6 are pixel maps (PPM).
+// The plain P2 and P3 variants write every value as ASCII text, the others as bytes.
+const (
+	PlainPGM  = "P2"
+	PlainPPM  = "P3"
+	BinaryPGM = "P5"
+	BinaryPPM = "P6"
+)
+
+// Decode a PPM or PGM file, in its plain or binary variant. Values are scaled from the
+// maximum value of the file to 8 bits, and gray levels are kept on every channel.
+// Files of more than max_image_size pixels are rejected before their pixels are decoded.
+func DecodeNetpbm(r io.Reader, max_image_size int) (Image, error) {
+	reader := bufio.NewReader(r)
+
+	format, err := netpbmToken(reader)
+	if err != nil {
+		return Image{}, err
+	}
+	if format != PlainPGM && format != PlainPPM && format != BinaryPGM && format != BinaryPPM {
+		return Image{}, fmt.Errorf("UNSUPPORTED NETPBM FORMAT %q", format)
+	}
+
+	var header [3]int
+	for i := range header {
+		token, err := netpbmToken(reader)
+		if err != nil {
+			return Image{}, err
+		}
+		if header[i], err = strconv.Atoi(token); err != nil {
+			return Image{}, fmt.Errorf("INVALID NETPBM HEADER %q", token)
+		}
+	}
+	width, height, maxval := header[0], header[1], header[2]
+
+	if width < 1 || height < 1 {
+		return Image{}, fmt.Errorf("INVALID IMAGE DIMENSIONS %dx%d", width, height)
+	}
+	if width > max_image_size || height > max_image_size || width*height > max_image_size {
+		return Image{}, fmt.Errorf("IMAGE OF %dx%d PIXELS EXCEEDS %d PIXELS", width, height, max_image_size)
+	}
+	if maxval < 1 || maxval > 65535 {
+		return Image{}, fmt.Errorf("INVALID NETPBM MAXIMUM VALUE %d", maxval)
+	}
+
+	img, err := NewImageOfSize("black", width, height)
+	if err != nil {
+		return Image{}, err
+	}
+
+	// Reading the maximum value consumed the single whitespace before the bytes of binary files
+	binary := format == BinaryPGM || format == BinaryPPM
+
+	channels := 3
+	if format == PlainPGM || format == BinaryPGM {
+		channels = 1
+	}
+
+	var values [3]uint8
+	for idx := range img.Pixels {
+		for c := range channels {
+			var value int
+			if binary {
+				value, err = netpbmSample(reader, maxval)
+			} else {
+				var token string
+				if token, err = netpbmToken(reader); err == nil {
+					value, err = strconv.Atoi(token)
+				}
+			}
+			if err != nil {
+				return Image{}, fmt.Errorf("TRUNCATED NETPBM FILE: %w", err)
+			}
+			if value < 0 || value > maxval {
+				return Image{}, fmt.Errorf("NETPBM VALUE %d EXCEEDS %d", value, maxval)
+			}
+
+			// Scale the value to 8 bits, rounding to the nearest one
+			values[c] = uint8((value*255 + maxval/2) / maxval)
+		}
+
+		if channels == 1 {
+			values[1], values[2] = values[0], values[0]
+		}
+		img.Pixels[idx] = Pixel{R: values[0], G: values[1], B: values[2]}
+	}
+
+	return img, nil
+}
+
+// Encode the image as a PPM file of the given format, P3 or P6, with 8-bit values.
+// The plain format writes one row of pixels per line.
+func EncodePPM(w io.Writer, img Image, format string) error {
+	if format != PlainPPM && format != BinaryPPM {
+		return fmt.Errorf("UNSUPPORTED PPM FORMAT %q", format)
+	}
+
+	return encodeNetpbm(w, img, format, func(pixel Pixel) []uint8 {
+		return []uint8{pixel.R, pixel.G, pixel.B}
+	})
+}
+
+// Encode a gray image as a PGM file of the given format, P2 or P5, with 8-bit values.
+// Every pixel must have the same R, G and B, so that the decoded image has the same pixels.
+func EncodePGM(w io.Writer, img Image, format string) error {
+	if format != PlainPGM && format != BinaryPGM {
+		return fmt.Errorf("UNSUPPORTED PGM FORMAT %q", format)
+	}
+
+	for idx, pixel := range img.Pixels {
+		if pixel.R != pixel.G || pixel.R != pixel.B {
+			return fmt.Errorf("PIXEL %d IS NOT GRAY", idx)
+		}
+	}
+
+	return encodeNetpbm(w, img, format, func(pixel Pixel) []uint8 {
+		return []uint8{pixel.R}
+	})
+}
+
+func encodeNetpbm(w io.Writer, img Image, format string, samples func(Pixel) []uint8) error {
+	width, height, err := img.Dimensions()
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "%s\n%d %d\n255\n", format, width, height)
+
+	plain := format == PlainPGM || format == PlainPPM
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			for i, sample := range samples(img.Pixels[row*width+col]) {
+				if !plain {
+					writer.WriteByte(sample)
+					continue
+				}
+				if col > 0 || i > 0 {
+					writer.WriteByte(' ')
+				}
+				writer.WriteString(strconv.Itoa(int(sample)))
+			}
+		}
+		if plain {
+			writer.WriteByte('\n')
+		}
+	}
+
+	return writer.Flush()
+}
+
+// Return the next whitespace separated token, skipping comments from '#' to the end of the line.
+func netpbmToken(reader *bufio.Reader) (string, error) {
+	var token []byte
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF && len(token) > 0 {
+			return string(token), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case b == '#' && len(token) == 0:
+			if _, err := reader.ReadString('\n'); err != nil && err != io.EOF {
+				return "", err
+			}
+		case b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' || b == '\f':
+			if len(token) > 0 {
+				return string(token), nil
+			}
+		default:
+			token = append(token, b)
+		}
+	}
+}
+
+// Return the next value of a binary file, one byte when the maximum value fits in a byte, two big-endian bytes otherwise.
+func netpbmSample(reader *bufio.Reader, maxval int) (int, error) {
+	high, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if maxval < 256 {
+		return int(high), nil
+	}
+
+	low, err := reader.ReadByte()
+	if err != nil {
+		return 0, errors.New("MISSING LOW BYTE")
+	}
+	return int(high)<<8 | int(low), nil
+}
@@ -0,0 +1,72 @@
+package image
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/consensys/gnark/test"
+)
+
+func TestNetpbmRoundTrip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Random images are gray, so they fit in every format
+	img, err := NewImageOfSize("random", 5, 3)
+	assert.NoError(err)
+
+	for _, format := range []string{PlainPGM, PlainPPM, BinaryPGM, BinaryPPM} {
+		var buf bytes.Buffer
+		if format == PlainPPM || format == BinaryPPM {
+			assert.NoError(EncodePPM(&buf, img, format))
+		} else {
+			assert.NoError(EncodePGM(&buf, img, format))
+		}
+
+		decoded, err := DecodeNetpbm(&buf, 15)
+		assert.NoError(err, format)
+		assert.Equal(img.Pixels, decoded.Pixels, format)
+		assert.Equal(img.Metadata.Width, decoded.Metadata.Width, format)
+		assert.Equal(img.Metadata.Height, decoded.Metadata.Height, format)
+	}
+
+	// Colors do not fit in a gray map
+	img.Pixels[0].R++
+	assert.Error(EncodePGM(&bytes.Buffer{}, img, BinaryPGM))
+}
+
+func TestNetpbmColorRoundTrip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Every channel of every pixel differs, so swapped channels are caught
+	img, err := NewImageOfSize("black", 4, 2)
+	assert.NoError(err)
+	for idx := range img.Pixels {
+		img.Pixels[idx] = Pixel{R: uint8(30 * idx), G: uint8(30*idx + 10), B: uint8(250 - 30*idx)}
+	}
+
+	for _, format := range []string{PlainPPM, BinaryPPM} {
+		var buf bytes.Buffer
+		assert.NoError(EncodePPM(&buf, img, format))
+
+		decoded, err := DecodeNetpbm(&buf, 8)
+		assert.NoError(err, format)
+		assert.Equal(img.Pixels, decoded.Pixels, format)
+	}
+}
+
+func TestNetpbmFixture(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	file, err := os.Open("testdata/ramp.pgm")
+	assert.NoError(err)
+	defer file.Close()
+
+	// Levels out of 15 are scaled to 8 bits
+	img, err := DecodeNetpbm(file, 8)
+	assert.NoError(err)
+	levels := []uint8{0, 85, 170, 255, 255, 170, 85, 0}
+	for idx, level := range levels {
+		assert.Equal(Pixel{R: level, G: level, B: level}, img.Pixels[idx])
+	}
+}
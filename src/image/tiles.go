@@ -0,0 +1,233 @@
+package image
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+// An image split in a grid of tiles, so that each tile fits in the circuits on its own.
+// The tiles are listed row by row, every tile of a row has the height of the row
+// and every tile of a column has the width of the column.
+type Tiling struct {
+	Columns []int // The width of each column of tiles, from left to right
+	Rows    []int // The height of each row of tiles, from top to bottom
+	Tiles   []Image
+}
+
+// Split the image in tiles of tile_size x tile_size pixels. When the width or height is
+// not a multiple of tile_size, the tiles of the last column or row are smaller.
+func (img Image) Tile(tile_size int) (Tiling, error) {
+	width, height, err := img.Dimensions()
+	if err != nil {
+		return Tiling{}, err
+	}
+	if tile_size < 1 {
+		return Tiling{}, fmt.Errorf("INVALID TILE SIZE %d", tile_size)
+	}
+
+	return img.TileGrid(tileLengths(width, tile_size), tileLengths(height, tile_size))
+}
+
+// Split the image in the grid of tiles with the given column widths and row heights.
+func (img Image) TileGrid(columns []int, rows []int) (Tiling, error) {
+	width, height, err := img.Dimensions()
+	if err != nil {
+		return Tiling{}, err
+	}
+	if sumLengths(columns) != width || sumLengths(rows) != height {
+		return Tiling{}, fmt.Errorf("TILE GRID DOES NOT COVER THE %dx%d IMAGE", width, height)
+	}
+
+	tiling := Tiling{Columns: columns, Rows: rows}
+	y0 := 0
+	for _, tileHeight := range rows {
+		x0 := 0
+		for _, tileWidth := range columns {
+			tile := Image{Pixels: make([]Pixel, tileWidth*tileHeight), Metadata: img.Metadata.Clone()}
+			for row := range tileHeight {
+				copy(tile.Pixels[row*tileWidth:(row+1)*tileWidth], img.Pixels[(y0+row)*width+x0:])
+			}
+
+			// Each tile is an image of its own
+			tile.Metadata.Width = tileWidth
+			tile.Metadata.Height = tileHeight
+			tiling.Tiles = append(tiling.Tiles, tile)
+
+			x0 += tileWidth
+		}
+		y0 += tileHeight
+	}
+
+	return tiling, nil
+}
+
+// Return the image the tiles were split from. The metadata is the one of the first tile,
+// with the width and height of the whole grid.
+func (tiling Tiling) Image() (Image, error) {
+	if err := tiling.check(); err != nil {
+		return Image{}, err
+	}
+
+	width, height := tiling.Dimensions()
+	img := Image{Pixels: make([]Pixel, width*height), Metadata: tiling.Tiles[0].Metadata.Clone()}
+	img.Metadata.Width = width
+	img.Metadata.Height = height
+
+	y0 := 0
+	for i, tileHeight := range tiling.Rows {
+		x0 := 0
+		for j, tileWidth := range tiling.Columns {
+			tile := tiling.Tiles[i*len(tiling.Columns)+j]
+			for row := range tileHeight {
+				copy(img.Pixels[(y0+row)*width+x0:], tile.Pixels[row*tileWidth:(row+1)*tileWidth])
+			}
+			x0 += tileWidth
+		}
+		y0 += tileHeight
+	}
+
+	return img, nil
+}
+
+// Return the width and height of the whole grid.
+func (tiling Tiling) Dimensions() (int, int) {
+	return sumLengths(tiling.Columns), sumLengths(tiling.Rows)
+}
+
+// Return the digest of every tile, in the order of the tiles.
+func (tiling Tiling) Digests(curve ecc.ID) ([][]byte, error) {
+	if err := tiling.check(); err != nil {
+		return nil, err
+	}
+
+	digests := make([][]byte, len(tiling.Tiles))
+	for i, tile := range tiling.Tiles {
+		digest, err := tile.Digest(curve)
+		if err != nil {
+			return nil, err
+		}
+		digests[i] = digest
+	}
+
+	return digests, nil
+}
+
+// Return the root of the tiling, what a camera signs instead of the digest of the whole image.
+func (tiling Tiling) Root(curve ecc.ID) ([]byte, error) {
+	digests, err := tiling.Digests(curve)
+	if err != nil {
+		return nil, err
+	}
+
+	return TileRoot(curve, tiling.Columns, tiling.Rows, digests)
+}
+
+// Check that every tile has the width of its column and the height of its row.
+func (tiling Tiling) check() error {
+	if len(tiling.Columns) == 0 || len(tiling.Rows) == 0 || len(tiling.Tiles) != len(tiling.Columns)*len(tiling.Rows) {
+		return errors.New("INVALID TILE GRID")
+	}
+
+	for i, tile := range tiling.Tiles {
+		width, height, err := tile.Dimensions()
+		if err != nil {
+			return err
+		}
+		if width != tiling.Columns[i%len(tiling.Columns)] || height != tiling.Rows[i/len(tiling.Columns)] {
+			return fmt.Errorf("TILE %d DOES NOT FIT IN THE TILE GRID", i)
+		}
+	}
+
+	return nil
+}
+
+// TileRoot returns the MiMC hash of the grid of a tiled image, followed by the root of the Merkle
+// tree whose leaves are the digests of its tiles, row by row. The grid is the number of columns,
+// the width of every column, the number of rows and the height of every row, so the root fixes
+// where every tile lies in the image. Each node of the tree is the MiMC hash of its two children,
+// a node without sibling is carried to the next level as is.
+func TileRoot(curve ecc.ID, columns []int, rows []int, digests [][]byte) ([]byte, error) {
+	if len(digests) == 0 || len(digests) != len(columns)*len(rows) {
+		return nil, errors.New("THE TILE DIGESTS DO NOT FILL THE TILE GRID")
+	}
+
+	hFunc, err := NewMiMC(curve)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every digest must be a single field element
+	level := make([][]byte, len(digests))
+	for i, digest := range digests {
+		if len(digest) != hFunc.BlockSize() {
+			return nil, fmt.Errorf("INVALID DIGEST OF TILE %d", i)
+		}
+		level[i] = digest
+	}
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+
+			hFunc.Reset()
+			hFunc.Write(level[i])
+			hFunc.Write(level[i+1])
+			next = append(next, hFunc.Sum(nil))
+		}
+		level = next
+	}
+
+	// The grid comes first, like the dimensions in the digest of an image
+	hFunc.Reset()
+	elem := make([]byte, hFunc.BlockSize())
+	for _, lengths := range [][]int{columns, rows} {
+		for _, v := range append([]int{len(lengths)}, lengths...) {
+			if v < 1 {
+				return nil, errors.New("INVALID TILE GRID")
+			}
+			binary.BigEndian.PutUint64(elem[len(elem)-8:], uint64(v))
+			hFunc.Write(elem)
+		}
+	}
+	hFunc.Write(level[0])
+
+	return hFunc.Sum(nil), nil
+}
+
+// Sign a digest, or a tile root, using the given secret key, which must be a key on the given curve.
+func SignDigest(digest []byte, secretKey signature.Signer, curve ecc.ID) ([]byte, error) {
+	hFunc, err := NewMiMC(curve)
+	if err != nil {
+		return nil, err
+	}
+
+	return secretKey.Sign(digest, hFunc)
+}
+
+// Split length in parts of tile_size, the last part holds the remainder.
+func tileLengths(length int, tile_size int) []int {
+	lengths := make([]int, 0, (length+tile_size-1)/tile_size)
+	for start := 0; start < length; start += tile_size {
+		lengths = append(lengths, min(tile_size, length-start))
+	}
+	return lengths
+}
+
+func sumLengths(lengths []int) int {
+	sum := 0
+	for _, length := range lengths {
+		if length < 1 {
+			return -1
+		}
+		sum += length
+	}
+	return sum
+}
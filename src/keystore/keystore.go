@@ -0,0 +1,260 @@
+// Package keystore persists the public parameters and camera signing keys to a directory,
+// so that circuits are not set up again on every run.
+package keystore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"src/circuits"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// Name of the file holding the camera's signing key.
+const signerFile = "signer.key"
+
+// Write the constraint systems, proving and verifying keys of the public parameters to dir,
+// together with a manifest describing them. A signer already saved in dir is kept.
+func Save(dir string, params circuits.PublicParams) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	manifest := Manifest{
+		Version:   Version,
+		Curve:     params.Curve.String(),
+		Backend:   Backend,
+		ImageSize: params.MaxImageSize,
+		Circuits:  map[string]CircuitEntry{},
+	}
+
+	// Keep the signer of a previous save over the same curve
+	if previous, err := ReadManifest(dir); err == nil && previous.Curve == manifest.Curve {
+		manifest.Signer = previous.Signer
+	}
+
+	for _, transformationType := range sortedTypes(params) {
+		keys := params.Keys[transformationType]
+		if keys.Compiled == nil || keys.ProvKey == nil || keys.VeriKey == nil {
+			return fmt.Errorf("INCOMPLETE KEYS FOR TRANSFORMATION TYPE %q", transformationType)
+		}
+
+		var entry CircuitEntry
+		var err error
+		if entry.ConstraintSystem, err = writeFile(dir, transformationType+".ccs", keys.Compiled); err != nil {
+			return err
+		}
+		if entry.ProvingKey, err = writeFile(dir, transformationType+".pk", keys.ProvKey); err != nil {
+			return err
+		}
+		if entry.VerifyingKey, err = writeFile(dir, transformationType+".vk", keys.VeriKey); err != nil {
+			return err
+		}
+		manifest.Circuits[transformationType] = entry
+	}
+
+	return writeManifest(dir, manifest)
+}
+
+// Read the public parameters saved in dir. Keys are refused if a file was modified or if the
+// running definition of a circuit no longer compiles to the constraint system they were set up for.
+func Load(dir string) (circuits.PublicParams, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return circuits.PublicParams{}, err
+	}
+
+	curve, err := ecc.IDFromString(manifest.Curve)
+	if err != nil {
+		return circuits.PublicParams{}, err
+	}
+
+	params := circuits.PublicParams{Curve: curve, MaxImageSize: manifest.ImageSize, Keys: map[string]circuits.TransformationKeys{}}
+	for transformationType, entry := range manifest.Circuits {
+		if err := checkCircuit(curve, transformationType, manifest.ImageSize, entry.ConstraintSystem.SHA256); err != nil {
+			return circuits.PublicParams{}, err
+		}
+
+		keys := circuits.TransformationKeys{
+			Compiled: groth16.NewCS(curve),
+			ProvKey:  groth16.NewProvingKey(curve),
+			VeriKey:  groth16.NewVerifyingKey(curve),
+		}
+		if err := readFile(dir, entry.ConstraintSystem, keys.Compiled); err != nil {
+			return circuits.PublicParams{}, err
+		}
+		if err := readFile(dir, entry.ProvingKey, keys.ProvKey); err != nil {
+			return circuits.PublicParams{}, err
+		}
+		if err := readFile(dir, entry.VerifyingKey, keys.VeriKey); err != nil {
+			return circuits.PublicParams{}, err
+		}
+		params.Keys[transformationType] = keys
+	}
+
+	return params, nil
+}
+
+// Write the camera's signing key next to the public parameters it proves against.
+// The parameters must have been saved in dir first.
+func SaveIdentity(dir string, identity circuits.CameraIdentity) error {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+
+	// The signer must be on the twisted Edwards curve of the saved parameters
+	signerBytes := identity.SecKey.Bytes()
+	sk, err := newSigner(manifest.Curve, signerBytes)
+	if err != nil {
+		return err
+	}
+	if reflect.TypeOf(sk) != reflect.TypeOf(identity.SecKey) {
+		return fmt.Errorf("SIGNER IS NOT A %s KEY", manifest.Curve)
+	}
+
+	entry := FileEntry{File: signerFile, SHA256: contentHash(signerBytes)}
+	if err := os.WriteFile(filepath.Join(dir, signerFile), signerBytes, 0o600); err != nil {
+		return err
+	}
+
+	manifest.Signer = &entry
+	return writeManifest(dir, manifest)
+}
+
+// Read the camera's signing key saved in dir.
+func LoadIdentity(dir string) (circuits.CameraIdentity, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return circuits.CameraIdentity{}, err
+	}
+	if manifest.Signer == nil {
+		return circuits.CameraIdentity{}, errors.New("NO SIGNER IN KEY STORE")
+	}
+
+	signerBytes, err := readChecked(dir, *manifest.Signer)
+	if err != nil {
+		return circuits.CameraIdentity{}, err
+	}
+
+	sk, err := newSigner(manifest.Curve, signerBytes)
+	if err != nil {
+		return circuits.CameraIdentity{}, err
+	}
+
+	return circuits.CameraIdentity{SecKey: sk, PublicKey: sk.Public()}, nil
+}
+
+// Compile the running definition of a circuit and check it matches the saved constraint system.
+func checkCircuit(curve ecc.ID, transformationType string, max_image_size int, ccsHash string) error {
+	circuit, err := circuits.CircuitOf(transformationType, max_image_size)
+	if err != nil {
+		return err
+	}
+
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if _, err := ccs.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	if contentHash(buf.Bytes()) != ccsHash {
+		return fmt.Errorf("KEYS WERE GENERATED FOR A DIFFERENT %s CIRCUIT", transformationType)
+	}
+
+	return nil
+}
+
+// Return a signer on the twisted Edwards curve of the given curve, set from its binary representation.
+func newSigner(curveName string, signerBytes []byte) (signature.Signer, error) {
+	curve, err := ecc.IDFromString(curveName)
+	if err != nil {
+		return nil, err
+	}
+
+	edID, err := circuits.EdwardsID(curve)
+	if err != nil {
+		return nil, err
+	}
+
+	sk, err := ceddsa.New(edID, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := sk.SetBytes(signerBytes)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(signerBytes) {
+		return nil, fmt.Errorf("SIGNER IS NOT A %s KEY", curveName)
+	}
+
+	return sk, nil
+}
+
+// Serialize object into dir/name and return its manifest entry.
+func writeFile(dir string, name string, object io.WriterTo) (FileEntry, error) {
+	var buf bytes.Buffer
+	if _, err := object.WriteTo(&buf); err != nil {
+		return FileEntry{}, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o644); err != nil {
+		return FileEntry{}, err
+	}
+
+	return FileEntry{File: name, SHA256: contentHash(buf.Bytes())}, nil
+}
+
+// Deserialize the file of the given entry into object.
+func readFile(dir string, entry FileEntry, object io.ReaderFrom) error {
+	data, err := readChecked(dir, entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = object.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// Read the file of the given entry and check its content hash.
+func readChecked(dir string, entry FileEntry) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(dir, entry.File))
+	if err != nil {
+		return nil, err
+	}
+
+	if contentHash(data) != entry.SHA256 {
+		return nil, fmt.Errorf("CONTENT OF %s DOES NOT MATCH THE MANIFEST", entry.File)
+	}
+
+	return data, nil
+}
+
+// Return the transformation types of the public parameters in a stable order.
+func sortedTypes(params circuits.PublicParams) []string {
+	types := make([]string, 0, len(params.Keys))
+	for transformationType := range params.Keys {
+		types = append(types, transformationType)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,141 @@
+package keystore
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"src/circuits"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+)
+
+// Return the serialization of object.
+func serialize(assert *test.Assert, object io.WriterTo) []byte {
+	var buf bytes.Buffer
+	_, err := object.WriteTo(&buf)
+	assert.NoError(err)
+	return buf.Bytes()
+}
+
+// Set up the identity circuit and save its keys to a new directory.
+func newTestStore(t *testing.T, assert *test.Assert) (string, circuits.PublicParams) {
+	params, err := circuits.Setup(4, ecc.BN254.ScalarField(), "identity")
+	assert.NoError(err)
+
+	dir := t.TempDir()
+	assert.NoError(Save(dir, params))
+	return dir, params
+}
+
+// Rewrite the manifest of dir with edit applied.
+func editManifest(assert *test.Assert, dir string, edit func(*Manifest)) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	assert.NoError(err)
+	var manifest Manifest
+	assert.NoError(json.Unmarshal(data, &manifest))
+
+	edit(&manifest)
+	assert.NoError(writeManifest(dir, manifest))
+}
+
+func TestSaveLoad(t *testing.T) {
+	assert := test.NewAssert(t)
+	dir, params := newTestStore(t, assert)
+
+	loaded, err := Load(dir)
+	assert.NoError(err)
+	assert.Equal(params.Curve, loaded.Curve)
+	assert.Equal(params.MaxImageSize, loaded.MaxImageSize)
+
+	saved, read := params.Keys["identity"], loaded.Keys["identity"]
+	assert.Equal(serialize(assert, saved.Compiled), serialize(assert, read.Compiled))
+	assert.Equal(serialize(assert, saved.ProvKey), serialize(assert, read.ProvKey))
+	assert.Equal(serialize(assert, saved.VeriKey), serialize(assert, read.VeriKey))
+}
+
+func TestLoadManifestMismatch(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Keys set up for a constraint system the running circuit no longer compiles to are stale,
+	// here the keys of the crop circuit saved as those of the identity circuit
+	params, err := circuits.Setup(4, ecc.BN254.ScalarField(), "identity", "crop")
+	assert.NoError(err)
+	dir := t.TempDir()
+	assert.NoError(Save(dir, params))
+	editManifest(assert, dir, func(manifest *Manifest) { manifest.Circuits["identity"] = manifest.Circuits["crop"] })
+	_, err = Load(dir)
+	assert.Error(err)
+
+	// So are keys for images of another size
+	dir, _ = newTestStore(t, assert)
+	editManifest(assert, dir, func(manifest *Manifest) { manifest.ImageSize = 5 })
+	_, err = Load(dir)
+	assert.Error(err)
+
+	// And a store of another layout is not read at all
+	dir, _ = newTestStore(t, assert)
+	editManifest(assert, dir, func(manifest *Manifest) { manifest.Version = Version + 1 })
+	_, err = Load(dir)
+	assert.Error(err)
+}
+
+func TestLoadTamperedFiles(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, file := range []string{"identity.ccs", "identity.pk", "identity.vk"} {
+		dir, _ := newTestStore(t, assert)
+
+		path := filepath.Join(dir, file)
+		data, err := os.ReadFile(path)
+		assert.NoError(err)
+		data[len(data)/2]++
+		assert.NoError(os.WriteFile(path, data, 0o644))
+
+		_, err = Load(dir)
+		assert.Error(err, file)
+	}
+}
+
+func TestSignerRoundTrip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	identity, err := circuits.NewCameraIdentity(ecc.BN254)
+	assert.NoError(err)
+
+	// The signer is saved next to the parameters it proves against
+	assert.Error(SaveIdentity(t.TempDir(), identity))
+	dir, params := newTestStore(t, assert)
+	_, err = LoadIdentity(dir)
+	assert.Error(err)
+	assert.NoError(SaveIdentity(dir, identity))
+
+	loaded, err := LoadIdentity(dir)
+	assert.NoError(err)
+	assert.True(loaded.PublicKey.Equal(identity.PublicKey))
+	assert.Equal(identity.SecKey.Bytes(), loaded.SecKey.Bytes())
+
+	// Saving the parameters again keeps the signer
+	assert.NoError(Save(dir, params))
+	loaded, err = LoadIdentity(dir)
+	assert.NoError(err)
+	assert.True(loaded.PublicKey.Equal(identity.PublicKey))
+
+	// A signer on another curve is refused
+	other, err := circuits.NewCameraIdentity(ecc.BLS12_377)
+	assert.NoError(err)
+	assert.Error(SaveIdentity(dir, other))
+
+	// And so is a modified one
+	path := filepath.Join(dir, signerFile)
+	data, err := os.ReadFile(path)
+	assert.NoError(err)
+	data[0]++
+	assert.NoError(os.WriteFile(path, data, 0o600))
+	_, err = LoadIdentity(dir)
+	assert.Error(err)
+}
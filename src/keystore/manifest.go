@@ -0,0 +1,84 @@
+package keystore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Version of the key-store layout, bumped whenever the files or the manifest change.
+const Version = 1
+
+// The only proving system the circuits are set up for.
+const Backend = "groth16"
+
+// Name of the manifest in a key-store directory.
+const ManifestFile = "manifest.json"
+
+// A file of the key store and the SHA-256 of its content.
+type FileEntry struct {
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+}
+
+// The files of one transformation circuit. The hash of the constraint system also
+// identifies the circuit definition the keys were generated for.
+type CircuitEntry struct {
+	ConstraintSystem FileEntry `json:"constraint_system"`
+	ProvingKey       FileEntry `json:"proving_key"`
+	VerifyingKey     FileEntry `json:"verifying_key"`
+}
+
+// Describes what a key-store directory holds and what it was generated for.
+type Manifest struct {
+	Version   int                     `json:"version"`
+	Curve     string                  `json:"curve"`
+	Backend   string                  `json:"backend"`
+	ImageSize int                     `json:"image_size"` // Maximum number of pixels of the images the circuits accept
+	Circuits  map[string]CircuitEntry `json:"circuits"`
+	Signer    *FileEntry              `json:"signer,omitempty"`
+}
+
+// Read the manifest of the given directory and check it was written for the running code.
+func ReadManifest(dir string) (Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, err
+	}
+
+	if manifest.Version != Version {
+		return Manifest{}, fmt.Errorf("UNSUPPORTED KEY STORE VERSION %d", manifest.Version)
+	}
+	if manifest.Backend != Backend {
+		return Manifest{}, fmt.Errorf("UNSUPPORTED BACKEND %q", manifest.Backend)
+	}
+	if manifest.ImageSize <= 0 {
+		return Manifest{}, fmt.Errorf("INVALID MAXIMUM IMAGE SIZE %d", manifest.ImageSize)
+	}
+
+	return manifest, nil
+}
+
+// Write the manifest to the given directory.
+func writeManifest(dir string, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, ManifestFile), data, 0o644)
+}
+
+// Return the hex encoded SHA-256 of data.
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
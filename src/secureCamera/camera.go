@@ -1,28 +1,45 @@
 package secureCamera
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"src/circuits"
 	"src/image"
-
-	"github.com/consensys/gnark-crypto/ecc"
+	"time"
 )
 
 type SecureCamera struct {
-	IdKeys   circuits.Keys
-	CropKeys circuits.Keys
+	Params   circuits.PublicParams   // Shared with every editor and verifier
+	Identity circuits.CameraIdentity // Only known to this camera
+	DeviceID string                  // Recorded in the metadata of every picture, derived from the public key
+	Prover   *circuits.Prover        // Reused for every picture
 	Pictures []image.Image
 	Proofs   []circuits.Proof
 }
 
-func NewCamera() (SecureCamera, error) {
+func NewCamera(params circuits.PublicParams) (SecureCamera, error) {
 	fmt.Println("[Camera] Generating new camera")
-	// 1. Generate keys
-	idKeys, cropKey, err := circuits.Generator(image.N, ecc.BN254.ScalarField()) // Generate a secret key for signing
+	// 1. Generate signing keys, the proving keys come from the public parameters
+	identity, err := circuits.NewCameraIdentity(params.Curve)
+	if err != nil {
+		return SecureCamera{}, err
+	}
+
+	prover, err := circuits.NewProver(params)
 	if err != nil {
 		return SecureCamera{}, err
 	}
 
-	return SecureCamera{IdKeys: idKeys, CropKeys: cropKey}, nil
+	// The device ID is the start of the fingerprint of the public key
+	fingerprint := sha256.Sum256(identity.PublicKey.Bytes())
+	deviceID := hex.EncodeToString(fingerprint[:8])
+
+	return SecureCamera{Params: params, Identity: identity, DeviceID: deviceID, Prover: prover}, nil
+
+}
 
+// Return the capture time of a picture taken now, to the second like the metadata records it.
+func captureTime() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
 }
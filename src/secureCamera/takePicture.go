@@ -5,8 +5,6 @@ import (
 	"src/circuits"
 	"src/image"
 	"src/transformations"
-
-	"github.com/consensys/gnark-crypto/ecc"
 )
 
 func (cam *SecureCamera) TakePicture(flag string, legalTransformation string) error {
@@ -16,35 +14,48 @@ func (cam *SecureCamera) TakePicture(flag string, legalTransformation string) er
 	if err != nil {
 		fmt.Println("Error while creating new image: " + err.Error())
 	}
+	img.Metadata.CaptureTime = captureTime()
+
+	return cam.Capture(img, legalTransformation)
+}
+
+// Sign and prove a picture the camera did not create itself, such as a decoded PNG or JPEG file.
+func (cam *SecureCamera) Capture(img image.Image, legalTransformation string) error {
+	width, height, err := img.Dimensions()
+	if err != nil {
+		return err
+	}
+
+	// The camera records which device signed the picture
+	img.Metadata = img.Metadata.Clone()
+	img.Metadata.DeviceID = cam.DeviceID
 
-	// Use the camera's key to sign the original picture
-	signature := img.Sign(cam.IdKeys.SecKey)
+	// The public parameters fix the curve of every key
+	curve := cam.Params.Curve
 
-	// Construct a proof with only the image and signature
-	proof := circuits.Proof{Signature: signature}
+	// Use the camera's key to sign the original picture, the proof only holds the signature
+	proof, err := circuits.NewCaptureProof(img, cam.Identity.SecKey, curve)
+	if err != nil {
+		return err
+	}
 
 	// Create permissible transformation(s)
 	if legalTransformation == "crop" {
 		// This cropT will not crop any of the pixels.
 		tr := transformations.CropT{
-			N:  image.N,
 			X0: 0,
 			Y0: 0,
-			X1: image.N - 1,
-			Y1: image.N - 1,
+			X1: width - 1,
+			Y1: height - 1,
 		}
 
 		// Create a pcd_proof using an identity crop transformation
 		fmt.Println("[Camera] Starting Crop Prover")
-		proof, img, err := tr.TransformAndProve(cam.CropKeys.ProvKey, cam.IdKeys.SecKey, img, proof, ecc.BN254.ScalarField())
+		proof, img, err := tr.TransformAndProve(cam.Prover, img, proof)
 		if err != nil {
 			return err
 		}
 
-		// Add the verifying and public keys.
-		proof.VK.VeriKey = cam.CropKeys.VeriKey.VeriKey
-		proof.VK.PublicKey = cam.IdKeys.VeriKey.PublicKey
-
 		// Save the image and proof on the camera.
 		cam.Pictures = append(cam.Pictures, img)
 		cam.Proofs = append(cam.Proofs, proof)
@@ -54,15 +65,11 @@ func (cam *SecureCamera) TakePicture(flag string, legalTransformation string) er
 		fmt.Println("[Camera] Starting Identity Prover")
 
 		// Create a pcd_proof
-		proof, img, err := tr.TransformAndProve(cam.IdKeys.ProvKey, cam.IdKeys.SecKey, img, proof, ecc.BN254.ScalarField())
+		proof, img, err := tr.TransformAndProve(cam.Prover, img, proof)
 		if err != nil {
 			return err
 		}
 
-		// Add the verifying and public keys.
-		proof.VK.VeriKey = cam.IdKeys.VeriKey.VeriKey
-		proof.VK.PublicKey = cam.IdKeys.VeriKey.PublicKey
-
 		// Save the image and proof on the camera.
 		cam.Pictures = append(cam.Pictures, img)
 		cam.Proofs = append(cam.Proofs, proof)
@@ -72,3 +79,12 @@ func (cam *SecureCamera) TakePicture(flag string, legalTransformation string) er
 	return nil
 
 }
+
+// Commit to the metadata of a picture, so that its owner can later disclose some of the
+// fields, such as the capture time, without revealing the others, such as the GPS position.
+func (cam *SecureCamera) CommitMetadata(img image.Image) (circuits.MetadataCommitment, error) {
+	img.Metadata = img.Metadata.Clone()
+	img.Metadata.DeviceID = cam.DeviceID
+
+	return circuits.CommitMetadata(img, cam.Identity.SecKey, cam.Params.Curve)
+}
@@ -0,0 +1,36 @@
+package secureCamera
+
+import (
+	"fmt"
+	"src/circuits"
+	"src/image"
+)
+
+// Take a picture too large for the circuits and sign the root of its tiles instead of its digest.
+// Every tile of tile_size x tile_size pixels fits in the circuits of the public parameters.
+func (cam *SecureCamera) TakeTiledPicture(flag string, width int, height int, tile_size int) (image.Tiling, circuits.TiledProof, error) {
+	fmt.Println("[Camera] Taking a tiled picture")
+	if tile_size < 1 || tile_size*tile_size > cam.Params.MaxImageSize {
+		return image.Tiling{}, circuits.TiledProof{}, fmt.Errorf("TILES OF %dx%d PIXELS DO NOT FIT IN %d PIXELS", tile_size, tile_size, cam.Params.MaxImageSize)
+	}
+
+	img, err := image.NewImageOfSize(flag, width, height)
+	if err != nil {
+		return image.Tiling{}, circuits.TiledProof{}, err
+	}
+	img.Metadata.DeviceID = cam.DeviceID
+	img.Metadata.CaptureTime = captureTime()
+
+	tiling, err := img.Tile(tile_size)
+	if err != nil {
+		return image.Tiling{}, circuits.TiledProof{}, err
+	}
+
+	// Use the camera's key to sign the root of the tiles
+	proof, err := circuits.NewTiledCaptureProof(tiling, cam.Identity.SecKey, cam.Params.Curve)
+	if err != nil {
+		return image.Tiling{}, circuits.TiledProof{}, err
+	}
+
+	return tiling, proof, nil
+}
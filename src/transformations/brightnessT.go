@@ -0,0 +1,89 @@
+package transformations
+
+import (
+	"fmt"
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Adds Delta to every channel of every pixel, each channel saturating at 0 and 255.
+// Delta is at most circuits.MaxBrightnessDelta in either direction.
+type BrightnessT struct {
+	Delta int
+}
+
+func (t BrightnessT) Transform(img image.Image) (image.Image, error) {
+	if _, _, err := img.Dimensions(); err != nil {
+		return image.Image{}, err
+	}
+
+	if t.Delta < -circuits.MaxBrightnessDelta || t.Delta > circuits.MaxBrightnessDelta {
+		return image.Image{}, fmt.Errorf("INVALID BRIGHTNESS DELTA %d: OUT OF [-%d, %d]", t.Delta, circuits.MaxBrightnessDelta, circuits.MaxBrightnessDelta)
+	}
+
+	// The adjusted image keeps the metadata of the image, with the adjustment in its history
+	img_bright := image.Image{
+		Pixels:   make([]image.Pixel, len(img.Pixels)),
+		Metadata: img.Metadata.Clone(),
+	}
+	img_bright.Metadata.History = append(img_bright.Metadata.History, t.GetType())
+
+	for idx, pixel := range img.Pixels {
+		img_bright.Pixels[idx] = image.Pixel{R: clamp(int(pixel.R) + t.Delta), G: clamp(int(pixel.G) + t.Delta), B: clamp(int(pixel.B) + t.Delta)}
+	}
+
+	return img_bright, nil
+}
+
+func (t BrightnessT) GetType() string {
+	return "brightness"
+}
+
+func (t BrightnessT) NewCircuit(img image.Image, brightImage image.Image, proof_in circuits.Proof, curve ecc.ID, max_image_size int) (circuits.BrightnessCircuit, error) {
+	// The camera's signature comes from the incoming proof, the public inputs commit to the adjusted image
+	publicInputs, err := circuits.NewTransformationInputs(curve, t.GetType(), proof_in, img, brightImage)
+	if err != nil {
+		return circuits.BrightnessCircuit{}, err
+	}
+
+	frImage, err := img.ToFrImage(curve, max_image_size)
+	if err != nil {
+		return circuits.BrightnessCircuit{}, err
+	}
+
+	return circuits.BrightnessCircuit{
+		PublicInputs: publicInputs,
+		FrImage:      frImage,
+		Params:       circuits.FrBrightnessT{Delta: frontend.Variable(t.Delta)},
+	}, nil
+}
+
+func (t BrightnessT) TransformAndProve(prover *circuits.Prover, img image.Image, proof_in circuits.Proof) (circuits.Proof, image.Image, error) {
+	// Transform the image
+	brightImage, err := t.Transform(img)
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	circuit, err := t.NewCircuit(img, brightImage, proof_in, prover.Curve(), prover.MaxImageSize())
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	// Prove with the prover's cached constraint system and proving key
+	// Delta is public, the verifier knows how much the brightness changed
+	proof, err := prover.Prove(t.GetType(), &circuit, img, proof_in, t.Delta)
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	return proof, brightImage, nil
+}
+
+// Return the value saturated to a channel, from 0 to 255.
+func clamp(value int) uint8 {
+	return uint8(min(max(value, 0), 255))
+}
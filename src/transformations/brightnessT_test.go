@@ -0,0 +1,99 @@
+package transformations
+
+import (
+	"testing"
+
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+)
+
+func TestBrightnessCircuitMatchesTransform(t *testing.T) {
+	assert := test.NewAssert(t)
+	maxImageSize := 20
+
+	circuit, err := circuits.CircuitOf("brightness", maxImageSize)
+	assert.NoError(err)
+
+	sk, err := circuits.NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+
+	// Channels that saturate in either direction, and ones that do not
+	img, err := image.NewImageOfSize("black", 3, 2)
+	assert.NoError(err)
+	copy(img.Pixels, []image.Pixel{
+		{R: 0, G: 255, B: 128}, {R: 10, G: 250, B: 63}, {R: 64, G: 191, B: 192},
+		{R: 1, G: 2, B: 3}, {R: 200, G: 100, B: 50}, {R: 255, G: 255, B: 255},
+	})
+
+	proof, err := circuits.NewCaptureProof(img, sk, ecc.BN254)
+	assert.NoError(err)
+
+	for _, delta := range []int{-circuits.MaxBrightnessDelta, -20, 0, 7, circuits.MaxBrightnessDelta} {
+		tr := BrightnessT{Delta: delta}
+		bright, err := tr.Transform(img)
+		assert.NoError(err)
+
+		assignment, err := tr.NewCircuit(img, bright, proof, ecc.BN254, maxImageSize)
+		assert.NoError(err)
+		assert.NoError(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+	}
+
+	tr := BrightnessT{Delta: 20}
+	bright, err := tr.Transform(img)
+	assert.NoError(err)
+	assert.Equal(image.Pixel{R: 20, G: 255, B: 148}, bright.Pixels[0])
+
+	// The output must saturate rather than wrap around
+	wrapped := image.Image{Pixels: append([]image.Pixel{}, bright.Pixels...), Metadata: bright.Metadata}
+	wrapped.Pixels[0].G = (255 + 20) % 256
+	assignment, err := tr.NewCircuit(img, wrapped, proof, ecc.BN254, maxImageSize)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+
+	// A delta beyond the policy bound is rejected natively and by the circuit
+	tr = BrightnessT{Delta: circuits.MaxBrightnessDelta + 1}
+	_, err = tr.Transform(img)
+	assert.Error(err)
+
+	bright = image.Image{Pixels: make([]image.Pixel, len(img.Pixels)), Metadata: bright.Metadata}
+	for idx, pixel := range img.Pixels {
+		bright.Pixels[idx] = image.Pixel{R: clamp(int(pixel.R) + tr.Delta), G: clamp(int(pixel.G) + tr.Delta), B: clamp(int(pixel.B) + tr.Delta)}
+	}
+	assignment, err = tr.NewCircuit(img, bright, proof, ecc.BN254, maxImageSize)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+}
+
+func TestBrightnessDeltaIsPublic(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := circuits.Setup(4, ecc.BN254.ScalarField(), "brightness")
+	assert.NoError(err)
+	prover, err := circuits.NewProver(params)
+	assert.NoError(err)
+	identity, err := circuits.NewCameraIdentity(params.Curve)
+	assert.NoError(err)
+
+	img, err := image.NewImageOfSize("random", 2, 2)
+	assert.NoError(err)
+	proof_in, err := circuits.NewCaptureProof(img, identity.SecKey, params.Curve)
+	assert.NoError(err)
+
+	proof, bright, err := BrightnessT{Delta: -20}.TransformAndProve(prover, img, proof_in)
+	assert.NoError(err)
+	assert.Equal([]int{-20}, proof.Parameters)
+
+	valid, err := circuits.Verifier(proof, bright, params.VerifyingKeys())
+	assert.NoError(err)
+	assert.True(valid)
+
+	// The verifier rejects the proof presented with another delta, or none
+	for _, parameters := range [][]int{{20}, {-19}, nil} {
+		proof.Parameters = parameters
+		_, err = circuits.Verifier(proof, bright, params.VerifyingKeys())
+		assert.Error(err)
+	}
+}
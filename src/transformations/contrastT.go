@@ -0,0 +1,103 @@
+package transformations
+
+import (
+	"fmt"
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Changes the contrast of every channel of every pixel by the factor Numerator/Denominator
+// around circuits.ContrastPivot, with the rounding and limits of the ContrastCircuit.
+type ContrastT struct {
+	Numerator   int
+	Denominator int
+}
+
+func (t ContrastT) Transform(img image.Image) (image.Image, error) {
+	if _, _, err := img.Dimensions(); err != nil {
+		return image.Image{}, err
+	}
+
+	// Check that the factor is within the limits of the circuit
+	if t.Denominator < 1 || t.Denominator > circuits.MaxContrastDenominator ||
+		t.Numerator > t.Denominator*circuits.MaxContrastFactor || t.Denominator > t.Numerator*circuits.MaxContrastFactor {
+		return image.Image{}, fmt.Errorf("INVALID CONTRAST FACTOR %d/%d", t.Numerator, t.Denominator)
+	}
+
+	// The adjusted image keeps the metadata of the image, with the adjustment in its history
+	img_contrast := image.Image{
+		Pixels:   make([]image.Pixel, len(img.Pixels)),
+		Metadata: img.Metadata.Clone(),
+	}
+	img_contrast.Metadata.History = append(img_contrast.Metadata.History, t.GetType())
+
+	for idx, pixel := range img.Pixels {
+		img_contrast.Pixels[idx] = image.Pixel{R: t.channel(pixel.R), G: t.channel(pixel.G), B: t.channel(pixel.B)}
+	}
+
+	return img_contrast, nil
+}
+
+// Return the channel with its contrast changed, the factor must be valid.
+func (t ContrastT) channel(c uint8) uint8 {
+	// round((c - pivot) * N / D) = floor((2 * (c - pivot) * N + D) / (2 * D)), halves are rounded up
+	scaled := 2*(int(c)-circuits.ContrastPivot)*t.Numerator + t.Denominator
+	divisor := 2 * t.Denominator
+	rounded := scaled / divisor
+	if scaled%divisor < 0 {
+		rounded--
+	}
+
+	return clamp(circuits.ContrastPivot + rounded)
+}
+
+func (t ContrastT) GetType() string {
+	return "contrast"
+}
+
+func (t ContrastT) NewCircuit(img image.Image, contrastImage image.Image, proof_in circuits.Proof, curve ecc.ID, max_image_size int) (circuits.ContrastCircuit, error) {
+	// The camera's signature comes from the incoming proof, the public inputs commit to the adjusted image
+	publicInputs, err := circuits.NewTransformationInputs(curve, t.GetType(), proof_in, img, contrastImage)
+	if err != nil {
+		return circuits.ContrastCircuit{}, err
+	}
+
+	frImage, err := img.ToFrImage(curve, max_image_size)
+	if err != nil {
+		return circuits.ContrastCircuit{}, err
+	}
+
+	return circuits.ContrastCircuit{
+		PublicInputs: publicInputs,
+		FrImage:      frImage,
+		Params: circuits.FrContrastT{
+			Numerator:   frontend.Variable(t.Numerator),
+			Denominator: frontend.Variable(t.Denominator),
+		},
+	}, nil
+}
+
+func (t ContrastT) TransformAndProve(prover *circuits.Prover, img image.Image, proof_in circuits.Proof) (circuits.Proof, image.Image, error) {
+	// Transform the image
+	contrastImage, err := t.Transform(img)
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	circuit, err := t.NewCircuit(img, contrastImage, proof_in, prover.Curve(), prover.MaxImageSize())
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	// Prove with the prover's cached constraint system and proving key,
+	// the factor is public so the verifier knows how much the contrast changed
+	proof, err := prover.Prove(t.GetType(), &circuit, img, proof_in, t.Numerator, t.Denominator)
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	return proof, contrastImage, nil
+}
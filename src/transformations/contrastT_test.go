@@ -0,0 +1,129 @@
+package transformations
+
+import (
+	"math/big"
+	"testing"
+
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+)
+
+// Factors at the limits of the circuit, and ones whose products round both ways.
+var contrastFactors = []ContrastT{
+	{Numerator: 1, Denominator: 4},
+	{Numerator: 1, Denominator: 3},
+	{Numerator: 2, Denominator: 3},
+	{Numerator: 1, Denominator: 1},
+	{Numerator: 3, Denominator: 2},
+	{Numerator: 7, Denominator: 5},
+	{Numerator: 255, Denominator: 256},
+	{Numerator: 4, Denominator: 1},
+}
+
+func TestContrastRounding(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Every channel matches pivot + (c - pivot) * factor rounded half up, then clamped
+	for _, tr := range contrastFactors {
+		factor := big.NewRat(int64(tr.Numerator), int64(tr.Denominator))
+		for c := 0; c < 256; c++ {
+			exact := new(big.Rat).Mul(big.NewRat(int64(c-circuits.ContrastPivot), 1), factor)
+			exact.Add(exact, big.NewRat(1, 2))
+			rounded := new(big.Int).Div(exact.Num(), exact.Denom()) // Euclidean division, the floor for a positive denominator
+
+			expected := min(max(circuits.ContrastPivot+rounded.Int64(), 0), 255)
+			assert.Equal(uint8(expected), tr.channel(uint8(c)), "channel %d, factor %d/%d", c, tr.Numerator, tr.Denominator)
+		}
+	}
+
+	for _, tr := range []ContrastT{{Numerator: 1, Denominator: 0}, {Numerator: 5, Denominator: 1}, {Numerator: 1, Denominator: 5}, {Numerator: -1, Denominator: 1}, {Numerator: 257, Denominator: 257}} {
+		img, err := image.NewImage("random")
+		assert.NoError(err)
+		_, err = tr.Transform(img)
+		assert.Error(err)
+	}
+}
+
+func TestContrastCircuitMatchesTransform(t *testing.T) {
+	assert := test.NewAssert(t)
+	maxImageSize := 90
+
+	circuit, err := circuits.CircuitOf("contrast", maxImageSize)
+	assert.NoError(err)
+
+	sk, err := circuits.NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+
+	// Every channel value appears in the image
+	img, err := image.NewImageOfSize("black", 10, 9)
+	assert.NoError(err)
+	for idx := range img.Pixels {
+		img.Pixels[idx] = image.Pixel{R: uint8(idx), G: uint8(idx + 90), B: uint8(min(idx+180, 255))}
+	}
+
+	proof, err := circuits.NewCaptureProof(img, sk, ecc.BN254)
+	assert.NoError(err)
+
+	for _, tr := range contrastFactors {
+		adjusted, err := tr.Transform(img)
+		assert.NoError(err)
+
+		// The circuit computes every channel exactly like the native transformation
+		assignment, err := tr.NewCircuit(img, adjusted, proof, ecc.BN254, maxImageSize)
+		assert.NoError(err)
+		assert.NoError(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()), "factor %d/%d", tr.Numerator, tr.Denominator)
+
+		// And nothing else, a channel rounded the other way is rejected
+		idx := 129 - 90
+		offByOne := image.Image{Pixels: append([]image.Pixel{}, adjusted.Pixels...), Metadata: adjusted.Metadata}
+		offByOne.Pixels[idx].G ^= 1
+		assignment, err = tr.NewCircuit(img, offByOne, proof, ecc.BN254, maxImageSize)
+		assert.NoError(err)
+		assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()), "factor %d/%d", tr.Numerator, tr.Denominator)
+	}
+
+	// A factor beyond the limits is rejected by the circuit as well
+	tr := ContrastT{Numerator: 5, Denominator: 1}
+	adjusted := image.Image{Pixels: make([]image.Pixel, len(img.Pixels)), Metadata: img.Metadata.Clone()}
+	adjusted.Metadata.History = append(adjusted.Metadata.History, tr.GetType())
+	for idx, pixel := range img.Pixels {
+		adjusted.Pixels[idx] = image.Pixel{R: tr.channel(pixel.R), G: tr.channel(pixel.G), B: tr.channel(pixel.B)}
+	}
+	assignment, err := tr.NewCircuit(img, adjusted, proof, ecc.BN254, maxImageSize)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+}
+
+func TestContrastFactorIsPublic(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := circuits.Setup(4, ecc.BN254.ScalarField(), "contrast")
+	assert.NoError(err)
+	prover, err := circuits.NewProver(params)
+	assert.NoError(err)
+	identity, err := circuits.NewCameraIdentity(params.Curve)
+	assert.NoError(err)
+
+	img, err := image.NewImageOfSize("random", 2, 2)
+	assert.NoError(err)
+	proof_in, err := circuits.NewCaptureProof(img, identity.SecKey, params.Curve)
+	assert.NoError(err)
+
+	proof, adjusted, err := ContrastT{Numerator: 3, Denominator: 2}.TransformAndProve(prover, img, proof_in)
+	assert.NoError(err)
+	assert.Equal([]int{3, 2}, proof.Parameters)
+
+	valid, err := circuits.Verifier(proof, adjusted, params.VerifyingKeys())
+	assert.NoError(err)
+	assert.True(valid)
+
+	// The verifier rejects the proof presented with another factor, even an equal one
+	for _, parameters := range [][]int{{2, 3}, {6, 4}, {3}, nil} {
+		proof.Parameters = parameters
+		_, err = circuits.Verifier(proof, adjusted, params.VerifyingKeys())
+		assert.Error(err)
+	}
+}
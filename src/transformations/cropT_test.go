@@ -0,0 +1,198 @@
+package transformations
+
+import (
+	"os"
+	"testing"
+
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/test"
+)
+
+func TestCropCircuitMatchesTransform(t *testing.T) {
+	assert := test.NewAssert(t)
+	maxImageSize := image.N * image.N
+
+	circuit, err := circuits.CircuitOf("crop", maxImageSize)
+	assert.NoError(err)
+
+	sk, err := circuits.NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+
+	img, err := image.NewImage("random")
+	assert.NoError(err)
+
+	// The editor only receives the camera's capture proof
+	proof, err := circuits.NewCaptureProof(img, sk, ecc.BN254)
+	assert.NoError(err)
+
+	tr := CropT{X0: 2, Y0: 3, X1: 9, Y1: 11}
+	cropped, err := tr.Transform(img)
+	assert.NoError(err)
+
+	// The circuit accepts the natively cropped image as its output.
+	assignment, err := tr.NewCircuit(img, cropped, proof, ecc.BN254, maxImageSize)
+	assert.NoError(err)
+	assert.NoError(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+
+	// But not the uncropped image.
+	assignment, err = tr.NewCircuit(img, img, proof, ecc.BN254, maxImageSize)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+
+	// Nor a cropped image whose metadata was not derived from the signed metadata.
+	for _, forge := range []func(*image.Metadata){
+		func(m *image.Metadata) { m.Author = "Mallory" },
+		func(m *image.Metadata) { m.DeviceID = "another camera" },
+		func(m *image.Metadata) { m.GPS = &image.GPS{} },
+		func(m *image.Metadata) { m.History = nil },
+		func(m *image.Metadata) { m.Width, m.Height = m.Height, m.Width },
+	} {
+		forged := image.Image{Pixels: cropped.Pixels, Metadata: cropped.Metadata.Clone()}
+		forge(&forged.Metadata)
+		assignment, err = tr.NewCircuit(img, forged, proof, ecc.BN254, maxImageSize)
+		assert.NoError(err)
+		assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+	}
+}
+
+func TestTiledCropCircuits(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Tiles of 5x5 pixels, the last column and row of the 14x14 image are 4 pixels wide
+	tileSize := 5
+	circuit, err := circuits.CircuitOf("crop", tileSize*tileSize)
+	assert.NoError(err)
+
+	sk, err := circuits.NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+
+	img, err := image.NewImage("random")
+	assert.NoError(err)
+	tiling, err := img.Tile(tileSize)
+	assert.NoError(err)
+
+	// The camera signs the root of the tiles
+	proof, err := circuits.NewTiledCaptureProof(tiling, sk, ecc.BN254)
+	assert.NoError(err)
+	valid, err := circuits.VerifyTiled(proof, img, nil, []signature.PublicKey{sk.Public()})
+	assert.NoError(err)
+	assert.True(valid)
+
+	tr := CropT{X0: 3, Y0: 2, X1: 11, Y1: 12}
+	crops, columns, rows, err := tr.TileCrops(tiling.Columns, tiling.Rows)
+	assert.NoError(err)
+	assert.Equal([]int{2, 5, 2}, columns)
+	assert.Equal([]int{3, 5, 3}, rows)
+
+	cropped := image.Tiling{Columns: columns, Rows: rows}
+	for _, crop := range crops {
+		tile := tiling.Tiles[crop.Source]
+		croppedTile, err := crop.Transform(tile)
+		assert.NoError(err)
+		cropped.Tiles = append(cropped.Tiles, croppedTile)
+
+		// Each tile is proven against its signed digest
+		tile_in, err := proof.TileInput(crop.Source)
+		assert.NoError(err)
+		assignment, err := crop.NewCircuit(tile, croppedTile, tile_in, ecc.BN254, tileSize*tileSize)
+		assert.NoError(err)
+		assert.NoError(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+	}
+
+	// The cropped tiles make up the cropped image
+	expected, err := tr.Transform(img)
+	assert.NoError(err)
+	actual, err := cropped.Image()
+	assert.NoError(err)
+	assert.Equal(expected.Pixels, actual.Pixels)
+}
+
+func TestTiledCropForgedGrid(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := circuits.Setup(16, ecc.BN254.ScalarField(), "crop")
+	assert.NoError(err)
+	prover, err := circuits.NewProver(params)
+	assert.NoError(err)
+	sk, err := circuits.NewSecretKey(params.Curve)
+	assert.NoError(err)
+	trustedKeys := []signature.PublicKey{sk.Public()}
+
+	// An 8x8 picture signed as 4 tiles of 4x4 pixels
+	img, err := image.NewImageOfSize("random", 8, 8)
+	assert.NoError(err)
+	tiling, err := img.Tile(4)
+	assert.NoError(err)
+	capture, err := circuits.NewTiledCaptureProof(tiling, sk, params.Curve)
+	assert.NoError(err)
+
+	// A crop across the 4 tiles verifies
+	proof, cropped, err := CropT{X0: 2, Y0: 1, X1: 5, Y1: 6}.TransformAndProveTiles(prover, tiling, capture)
+	assert.NoError(err)
+	croppedImg, err := cropped.Image()
+	assert.NoError(err)
+	valid, err := circuits.VerifyTiled(proof, croppedImg, params.VerifyingKeys(), trustedKeys)
+	assert.NoError(err)
+	assert.True(valid)
+
+	// The top half of every tile, stacked in a column, is not a crop of the picture: the signed
+	// tiles cannot be claimed to be a column of 8x2 tiles, although the grid has the same size
+	forged := capture
+	forged.Transformation = "crop"
+	forged.Input_Columns, forged.Input_Rows = []int{8}, []int{2, 2, 2, 2}
+	forged.Output_Columns, forged.Output_Rows = []int{4}, []int{2, 2, 2, 2}
+	stacked := image.Tiling{Columns: forged.Output_Columns, Rows: forged.Output_Rows}
+	for i, tile := range tiling.Tiles {
+		tile_in, err := capture.TileInput(i)
+		assert.NoError(err)
+		tileProof, half, err := CropT{X0: 0, Y0: 0, X1: 3, Y1: 1}.TransformAndProve(prover, tile, tile_in)
+		assert.NoError(err)
+		forged.Tiles = append(forged.Tiles, tileProof)
+		stacked.Tiles = append(stacked.Tiles, half)
+	}
+	stackedImg, err := stacked.Image()
+	assert.NoError(err)
+	_, err = circuits.VerifyTiled(forged, stackedImg, params.VerifyingKeys(), trustedKeys)
+	assert.Error(err)
+}
+
+// Decode a Netpbm fixture of the testdata directory.
+func readFixture(assert *test.Assert, name string) image.Image {
+	file, err := os.Open("testdata/" + name)
+	assert.NoError(err)
+	defer file.Close()
+
+	img, err := image.DecodeNetpbm(file, image.N*image.N)
+	assert.NoError(err)
+	return img
+}
+
+func TestCropFixture(t *testing.T) {
+	assert := test.NewAssert(t)
+	img := readFixture(assert, "gradient.ppm")
+	golden := readFixture(assert, "gradient_crop.ppm")
+
+	tr := CropT{X0: 1, Y0: 1, X1: 4, Y1: 3}
+	cropped, err := tr.Transform(img)
+	assert.NoError(err)
+	assert.Equal(golden.Pixels, cropped.Pixels)
+	assert.Equal(golden.Metadata.Width, cropped.Metadata.Width)
+	assert.Equal(golden.Metadata.Height, cropped.Metadata.Height)
+
+	// The circuit accepts the golden pixels, with the metadata derived from the signed image
+	golden.Metadata = cropped.Metadata
+	sk, err := circuits.NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+	proof, err := circuits.NewCaptureProof(img, sk, ecc.BN254)
+	assert.NoError(err)
+
+	circuit, err := circuits.CircuitOf("crop", 30)
+	assert.NoError(err)
+	assignment, err := tr.NewCircuit(img, golden, proof, ecc.BN254, 30)
+	assert.NoError(err)
+	assert.NoError(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+}
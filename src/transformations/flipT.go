@@ -0,0 +1,89 @@
+package transformations
+
+import (
+	"errors"
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Mirrors a picture left to right when Horizontal is set, and top to bottom when Vertical is set.
+type FlipT struct {
+	Horizontal bool
+	Vertical   bool
+}
+
+func (t FlipT) Transform(img image.Image) (image.Image, error) {
+	width, height, err := img.Dimensions()
+	if err != nil {
+		return image.Image{}, err
+	}
+
+	if !t.Horizontal && !t.Vertical {
+		return image.Image{}, errors.New("INVALID FLIP: NOTHING TO MIRROR")
+	}
+
+	// The flipped image keeps the metadata of the image, with the flip in its history
+	img_flipped := image.Image{
+		Pixels:   make([]image.Pixel, len(img.Pixels)),
+		Metadata: img.Metadata.Clone(),
+	}
+	img_flipped.Metadata.History = append(img_flipped.Metadata.History, t.GetType())
+
+	// The pixel at (row, col) of the flipped image is the pixel at the mirrored row and column of the image
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			srcRow, srcCol := row, col
+			if t.Vertical {
+				srcRow = height - 1 - row
+			}
+			if t.Horizontal {
+				srcCol = width - 1 - col
+			}
+			img_flipped.Pixels[row*width+col] = img.Pixels[srcRow*width+srcCol]
+		}
+	}
+
+	return img_flipped, nil
+}
+
+func (t FlipT) GetType() string {
+	return circuits.FlipType(t.Horizontal, t.Vertical)
+}
+
+func (t FlipT) NewCircuit(img image.Image, flippedImage image.Image, proof_in circuits.Proof, curve ecc.ID, max_image_size int) (circuits.FlipCircuit, error) {
+	// The camera's signature comes from the incoming proof, the public inputs commit to the flipped image
+	publicInputs, err := circuits.NewTransformationInputs(curve, t.GetType(), proof_in, img, flippedImage)
+	if err != nil {
+		return circuits.FlipCircuit{}, err
+	}
+
+	frImage, err := img.ToFrImage(curve, max_image_size)
+	if err != nil {
+		return circuits.FlipCircuit{}, err
+	}
+
+	return circuits.FlipCircuit{PublicInputs: publicInputs, FrImage: frImage, Horizontal: t.Horizontal, Vertical: t.Vertical}, nil
+}
+
+func (t FlipT) TransformAndProve(prover *circuits.Prover, img image.Image, proof_in circuits.Proof) (circuits.Proof, image.Image, error) {
+	// Transform the image
+	flippedImage, err := t.Transform(img)
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	circuit, err := t.NewCircuit(img, flippedImage, proof_in, prover.Curve(), prover.MaxImageSize())
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	// Prove with the prover's cached constraint system and proving key
+	proof, err := prover.Prove(t.GetType(), &circuit, img, proof_in)
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	return proof, flippedImage, nil
+}
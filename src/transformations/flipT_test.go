@@ -0,0 +1,95 @@
+package transformations
+
+import (
+	"slices"
+	"testing"
+
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+)
+
+func TestFlip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// A 3x2 image whose pixels are numbered row by row
+	img, err := image.NewImageOfSize("black", 3, 2)
+	assert.NoError(err)
+	for idx := range img.Pixels {
+		img.Pixels[idx] = image.Pixel{R: uint8(idx), B: 9}
+	}
+
+	for _, c := range []struct {
+		tr    FlipT
+		order []uint8
+	}{
+		{FlipT{Horizontal: true}, []uint8{2, 1, 0, 5, 4, 3}},
+		{FlipT{Vertical: true}, []uint8{3, 4, 5, 0, 1, 2}},
+		{FlipT{Horizontal: true, Vertical: true}, []uint8{5, 4, 3, 2, 1, 0}},
+	} {
+		flipped, err := c.tr.Transform(img)
+		assert.NoError(err)
+		assert.Equal(3, flipped.Metadata.Width)
+		assert.Equal(2, flipped.Metadata.Height)
+		for idx, pixel := range flipped.Pixels {
+			assert.Equal(c.order[idx], pixel.R)
+		}
+	}
+
+	_, err = FlipT{}.Transform(img)
+	assert.Error(err)
+}
+
+func TestFlipCircuitMatchesTransform(t *testing.T) {
+	assert := test.NewAssert(t)
+	maxImageSize := 20
+
+	sk, err := circuits.NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+
+	for _, tr := range []FlipT{{Horizontal: true}, {Vertical: true}, {Horizontal: true, Vertical: true}} {
+		circuit, err := circuits.CircuitOf(tr.GetType(), maxImageSize)
+		assert.NoError(err)
+
+		// Non-square images, a single row or column, a single pixel and an image of exactly the maximum size
+		for _, size := range [][2]int{{5, 3}, {3, 5}, {1, 6}, {6, 1}, {1, 1}, {4, 5}} {
+			img := newTestImage(assert, size[0], size[1])
+			proof, err := circuits.NewCaptureProof(img, sk, ecc.BN254)
+			assert.NoError(err)
+
+			flipped, err := tr.Transform(img)
+			assert.NoError(err)
+
+			// The circuit accepts the natively flipped image as its output
+			assignment, err := tr.NewCircuit(img, flipped, proof, ecc.BN254, maxImageSize)
+			assert.NoError(err)
+			assert.NoError(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()), "%+v of %dx%d", tr, size[0], size[1])
+
+			// But not with a channel off by one
+			offByOne := image.Image{Pixels: append([]image.Pixel{}, flipped.Pixels...), Metadata: flipped.Metadata}
+			offByOne.Pixels[len(offByOne.Pixels)-1].G ^= 1
+			assignment, err = tr.NewCircuit(img, offByOne, proof, ecc.BN254, maxImageSize)
+			assert.NoError(err)
+			assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()), "%+v of %dx%d", tr, size[0], size[1])
+
+			// Nor the unflipped pixels, small images can be their own flip
+			if !slices.Equal(img.Pixels, flipped.Pixels) {
+				unflipped := image.Image{Pixels: img.Pixels, Metadata: flipped.Metadata}
+				assignment, err = tr.NewCircuit(img, unflipped, proof, ecc.BN254, maxImageSize)
+				assert.NoError(err)
+				assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()), "%+v of %dx%d", tr, size[0], size[1])
+			}
+		}
+
+		// An image larger than the circuit is refused
+		img := newTestImage(assert, 7, 3)
+		proof, err := circuits.NewCaptureProof(img, sk, ecc.BN254)
+		assert.NoError(err)
+		flipped, err := tr.Transform(img)
+		assert.NoError(err)
+		_, err = tr.NewCircuit(img, flipped, proof, ecc.BN254, maxImageSize)
+		assert.Error(err)
+	}
+}
@@ -0,0 +1,72 @@
+package transformations
+
+import (
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Converts a picture to shades of gray, every pixel becomes the gray pixel of its luma.
+type GrayscaleT struct {
+}
+
+func (t GrayscaleT) Transform(img image.Image) (image.Image, error) {
+	if _, _, err := img.Dimensions(); err != nil {
+		return image.Image{}, err
+	}
+
+	// The gray image keeps the metadata of the image, with the conversion in its history
+	img_gray := image.Image{
+		Pixels:   make([]image.Pixel, len(img.Pixels)),
+		Metadata: img.Metadata.Clone(),
+	}
+	img_gray.Metadata.History = append(img_gray.Metadata.History, t.GetType())
+
+	for idx, pixel := range img.Pixels {
+		luma := pixel.Luma()
+		img_gray.Pixels[idx] = image.Pixel{R: luma, G: luma, B: luma}
+	}
+
+	return img_gray, nil
+}
+
+func (t GrayscaleT) GetType() string {
+	return "grayscale"
+}
+
+func (t GrayscaleT) NewCircuit(img image.Image, grayImage image.Image, proof_in circuits.Proof, curve ecc.ID, max_image_size int) (circuits.GrayscaleCircuit, error) {
+	// The camera's signature comes from the incoming proof, the public inputs commit to the gray image
+	publicInputs, err := circuits.NewTransformationInputs(curve, t.GetType(), proof_in, img, grayImage)
+	if err != nil {
+		return circuits.GrayscaleCircuit{}, err
+	}
+
+	frImage, err := img.ToFrImage(curve, max_image_size)
+	if err != nil {
+		return circuits.GrayscaleCircuit{}, err
+	}
+
+	return circuits.GrayscaleCircuit{PublicInputs: publicInputs, FrImage: frImage}, nil
+}
+
+func (t GrayscaleT) TransformAndProve(prover *circuits.Prover, img image.Image, proof_in circuits.Proof) (circuits.Proof, image.Image, error) {
+	// Transform the image
+	grayImage, err := t.Transform(img)
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	circuit, err := t.NewCircuit(img, grayImage, proof_in, prover.Curve(), prover.MaxImageSize())
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	// Prove with the prover's cached constraint system and proving key
+	proof, err := prover.Prove(t.GetType(), &circuit, img, proof_in)
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	return proof, grayImage, nil
+}
@@ -0,0 +1,61 @@
+package transformations
+
+import (
+	"testing"
+
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+)
+
+func TestGrayscaleCircuitMatchesTransform(t *testing.T) {
+	assert := test.NewAssert(t)
+	maxImageSize := 20
+
+	circuit, err := circuits.CircuitOf("grayscale", maxImageSize)
+	assert.NoError(err)
+
+	sk, err := circuits.NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+
+	// Colors whose weighted sums round up and down, and the extremes
+	img, err := image.NewImageOfSize("black", 4, 3)
+	assert.NoError(err)
+	copy(img.Pixels, []image.Pixel{
+		{R: 255, G: 255, B: 255}, {R: 255, G: 0, B: 0}, {R: 0, G: 255, B: 0}, {R: 0, G: 0, B: 255},
+		{R: 1, G: 1, B: 2}, {R: 3, G: 0, B: 0}, {R: 2, G: 0, B: 0}, {R: 12, G: 200, B: 99},
+		{R: 128, G: 128, B: 128}, {R: 0, G: 1, B: 0}, {R: 200, G: 100, B: 50}, {R: 254, G: 255, B: 255},
+	})
+
+	proof, err := circuits.NewCaptureProof(img, sk, ecc.BN254)
+	assert.NoError(err)
+
+	tr := GrayscaleT{}
+	gray, err := tr.Transform(img)
+	assert.NoError(err)
+	assert.Equal(image.Pixel{R: 255, G: 255, B: 255}, gray.Pixels[0])
+	assert.Equal(image.Pixel{R: 77, G: 77, B: 77}, gray.Pixels[1])
+	assert.Equal(image.Pixel{R: 1, G: 1, B: 1}, gray.Pixels[5])
+	assert.Equal(image.Pixel{R: 128, G: 128, B: 128}, gray.Pixels[8])
+	assert.Equal(image.Pixel{R: 1, G: 1, B: 1}, gray.Pixels[9])
+	assert.Equal([]string{"grayscale"}, gray.Metadata.History)
+
+	// The circuit accepts the natively converted image as its output
+	assignment, err := tr.NewCircuit(img, gray, proof, ecc.BN254, maxImageSize)
+	assert.NoError(err)
+	assert.NoError(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+
+	// But not the colored image, nor a luma rounded the other way
+	colored := image.Image{Pixels: img.Pixels, Metadata: gray.Metadata}
+	assignment, err = tr.NewCircuit(img, colored, proof, ecc.BN254, maxImageSize)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+
+	offByOne := image.Image{Pixels: append([]image.Pixel{}, gray.Pixels...), Metadata: gray.Metadata}
+	offByOne.Pixels[5] = image.Pixel{}
+	assignment, err = tr.NewCircuit(img, offByOne, proof, ecc.BN254, maxImageSize)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()))
+}
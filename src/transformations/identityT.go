@@ -1,16 +1,10 @@
 package transformations
 
 import (
-	"math/big"
 	"src/circuits"
 	"src/image"
 
 	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark-crypto/signature"
-	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/r1cs"
-	"github.com/consensys/gnark/std/signature/eddsa"
 )
 
 type IdentityT struct {
@@ -28,60 +22,40 @@ func (t IdentityT) GetType() string {
 	return "identity"
 }
 
-func (t IdentityT) NewCircuit(img image.Image, secretKey signature.Signer) (circuits.IdentityCircuit, error) {
-	digSig := img.Sign(secretKey) // Sign the image, get the Public and Secret Key
-
-	// Assign the PK & SK to their eddsa equivilant
-	var eddsa_digSig eddsa.Signature
-	var eddsa_PK eddsa.PublicKey
-
-	pk := secretKey.Public()
-
-	eddsa_digSig.Assign(1, digSig)
-	eddsa_PK.Assign(1, pk.Bytes())
-
-	// Instantiate a new CropCircuit
-	circuit := circuits.IdentityCircuit{
-		PublicKey:       eddsa_PK,
-		EdDSA_Signature: eddsa_digSig,
-		ImageBytes:      img.ToBigEndian(),
-	}
-
-	return circuit, nil
-}
-
-func (t IdentityT) TransformAndProve(proving_key groth16.ProvingKey, secretKey signature.Signer, img image.Image, proof_in circuits.Proof, security_parameter *big.Int) (circuits.Proof, image.Image, error) {
-	// Create a new IdentityCircuit struct using the image_in and a secret key
-	circuit, err := t.NewCircuit(img, secretKey)
+func (t IdentityT) NewCircuit(img image.Image, proof_in circuits.Proof, curve ecc.ID, max_image_size int) (circuits.IdentityCircuit, error) {
+	// The camera's signature comes from the incoming proof, the output image is the input image
+	publicInputs, err := circuits.NewTransformationInputs(curve, t.GetType(), proof_in, img, img)
 	if err != nil {
-		return circuits.Proof{}, image.Image{}, err
+		return circuits.IdentityCircuit{}, err
 	}
 
-	// Create the secret witness from the circuit
-	secret_witness, err := frontend.NewWitness(&circuit, ecc.BN254.ScalarField())
+	frImage, err := img.ToFrImage(curve, max_image_size)
 	if err != nil {
-		return circuits.Proof{}, image.Image{}, err
+		return circuits.IdentityCircuit{}, err
 	}
 
-	// Set the security parameter and compile a constraint system (aka compliance_predicate)
-	compliance_predicate, err := frontend.Compile(security_parameter, r1cs.NewBuilder, &circuits.IdentityCircuit{})
-	if err != nil {
-		return circuits.Proof{}, image.Image{}, err
+	// Instantiate a new IdentityCircuit
+	circuit := circuits.IdentityCircuit{
+		PublicInputs: publicInputs,
+		FrImage:      frImage,
 	}
 
-	// Prove the secret witness adheres to the compliance predicate, using the given proving key
-	pcd_proof, err := groth16.Prove(compliance_predicate, proving_key, secret_witness)
+	return circuit, nil
+}
+
+func (t IdentityT) TransformAndProve(prover *circuits.Prover, img image.Image, proof_in circuits.Proof) (circuits.Proof, image.Image, error) {
+	// Create a new IdentityCircuit struct using the image_in and the incoming proof
+	circuit, err := t.NewCircuit(img, proof_in, prover.Curve(), prover.MaxImageSize())
 	if err != nil {
 		return circuits.Proof{}, image.Image{}, err
 	}
 
-	// Create a public witness
-	publicWitness, err := secret_witness.Public()
+	// Prove with the prover's cached constraint system and proving key
+	proof, err := prover.Prove(t.GetType(), &circuit, img, proof_in)
 	if err != nil {
 		return circuits.Proof{}, image.Image{}, err
 	}
 
-	proof := circuits.Proof{PCD_Proof: pcd_proof, Signature: proof_in.Signature, Public_Witness: publicWitness}
-	// Return the proof, image, signature and public witness.
+	// Return the proof and the image.
 	return proof, img, nil
 }
@@ -0,0 +1,114 @@
+package transformations
+
+import (
+	"fmt"
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Turns a picture clockwise by Quarters quarter turns, a negative number turns it counterclockwise.
+// The number of quarter turns is taken modulo 4 and must not be a whole number of turns.
+type RotateT struct {
+	Quarters int
+}
+
+func (t RotateT) Transform(img image.Image) (image.Image, error) {
+	width, height, err := img.Dimensions()
+	if err != nil {
+		return image.Image{}, err
+	}
+
+	quarters := t.quarters()
+	if quarters == 0 {
+		return image.Image{}, fmt.Errorf("INVALID ROTATION OF %d QUARTER TURNS", t.Quarters)
+	}
+
+	// Quarter turns swap the width and the height
+	newWidth, newHeight := width, height
+	if quarters%2 == 1 {
+		newWidth, newHeight = height, width
+	}
+
+	img_rotated := image.Image{
+		Pixels:   make([]image.Pixel, len(img.Pixels)),
+		Metadata: img.Metadata.Clone(),
+	}
+	img_rotated.Metadata.Width = newWidth
+	img_rotated.Metadata.Height = newHeight
+	img_rotated.Metadata.History = append(img_rotated.Metadata.History, t.GetType())
+
+	// The pixel at (row, col) of the rotated image, with the same formulas as the RotateCircuit
+	for row := 0; row < newHeight; row++ {
+		for col := 0; col < newWidth; col++ {
+			var srcRow, srcCol int
+			switch quarters {
+			case 1:
+				srcRow, srcCol = height-1-col, row
+			case 2:
+				srcRow, srcCol = height-1-row, width-1-col
+			case 3:
+				srcRow, srcCol = col, width-1-row
+			}
+			img_rotated.Pixels[row*newWidth+col] = img.Pixels[srcRow*width+srcCol]
+		}
+	}
+
+	return img_rotated, nil
+}
+
+// Return the number of clockwise quarter turns, from 0 to 3.
+func (t RotateT) quarters() int {
+	return ((t.Quarters % 4) + 4) % 4
+}
+
+func (t RotateT) GetType() string {
+	return "rotate"
+}
+
+func (t RotateT) NewCircuit(img image.Image, rotatedImage image.Image, proof_in circuits.Proof, curve ecc.ID, max_image_size int) (circuits.RotateCircuit, error) {
+	if t.quarters() == 0 {
+		return circuits.RotateCircuit{}, fmt.Errorf("INVALID ROTATION OF %d QUARTER TURNS", t.Quarters)
+	}
+
+	// The camera's signature comes from the incoming proof, the public inputs commit to the rotated image
+	publicInputs, err := circuits.NewTransformationInputs(curve, t.GetType(), proof_in, img, rotatedImage)
+	if err != nil {
+		return circuits.RotateCircuit{}, err
+	}
+
+	frImage, err := img.ToFrImage(curve, max_image_size)
+	if err != nil {
+		return circuits.RotateCircuit{}, err
+	}
+
+	return circuits.RotateCircuit{
+		PublicInputs: publicInputs,
+		FrImage:      frImage,
+		Params:       circuits.FrRotateT{Quarters: frontend.Variable(t.quarters())},
+	}, nil
+}
+
+func (t RotateT) TransformAndProve(prover *circuits.Prover, img image.Image, proof_in circuits.Proof) (circuits.Proof, image.Image, error) {
+	// Transform the image
+	rotatedImage, err := t.Transform(img)
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	circuit, err := t.NewCircuit(img, rotatedImage, proof_in, prover.Curve(), prover.MaxImageSize())
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	// Prove with the prover's cached constraint system and proving key
+	// The number of quarter turns is public, the verifier knows which way the picture was turned
+	proof, err := prover.Prove(t.GetType(), &circuit, img, proof_in, t.quarters())
+	if err != nil {
+		return circuits.Proof{}, image.Image{}, err
+	}
+
+	return proof, rotatedImage, nil
+}
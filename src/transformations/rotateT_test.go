@@ -0,0 +1,151 @@
+package transformations
+
+import (
+	"slices"
+	"testing"
+
+	"src/circuits"
+	"src/image"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+)
+
+// Return an image of the given size whose pixels all differ, with varied channels.
+func newTestImage(assert *test.Assert, width int, height int) image.Image {
+	img, err := image.NewImageOfSize("black", width, height)
+	assert.NoError(err)
+	for idx := range img.Pixels {
+		img.Pixels[idx] = image.Pixel{R: uint8(37 * idx), G: uint8(91*idx + 13), B: uint8(255 - 17*idx)}
+	}
+	return img
+}
+
+func TestRotate(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// A 3x2 image whose pixels are numbered row by row
+	img, err := image.NewImageOfSize("black", 3, 2)
+	assert.NoError(err)
+	for idx := range img.Pixels {
+		img.Pixels[idx] = image.Pixel{R: uint8(idx)}
+	}
+
+	for _, c := range []struct {
+		quarters      int
+		width, height int
+		order         []uint8
+	}{
+		{1, 2, 3, []uint8{3, 0, 4, 1, 5, 2}},
+		{2, 3, 2, []uint8{5, 4, 3, 2, 1, 0}},
+		{3, 2, 3, []uint8{2, 5, 1, 4, 0, 3}},
+		{-1, 2, 3, []uint8{2, 5, 1, 4, 0, 3}},
+	} {
+		rotated, err := RotateT{Quarters: c.quarters}.Transform(img)
+		assert.NoError(err)
+		assert.Equal(c.width, rotated.Metadata.Width)
+		assert.Equal(c.height, rotated.Metadata.Height)
+		for idx, pixel := range rotated.Pixels {
+			assert.Equal(c.order[idx], pixel.R)
+		}
+	}
+
+	// Four quarter turns give the image back, but a whole turn is not a rotation
+	rotated := img
+	for range 4 {
+		rotated, err = RotateT{Quarters: 1}.Transform(rotated)
+		assert.NoError(err)
+	}
+	assert.Equal(img.Pixels, rotated.Pixels)
+	assert.Equal([]string{"rotate", "rotate", "rotate", "rotate"}, rotated.Metadata.History)
+
+	_, err = RotateT{Quarters: 4}.Transform(img)
+	assert.Error(err)
+}
+
+func TestRotateCircuitMatchesTransform(t *testing.T) {
+	assert := test.NewAssert(t)
+	maxImageSize := 20
+
+	circuit, err := circuits.CircuitOf("rotate", maxImageSize)
+	assert.NoError(err)
+
+	sk, err := circuits.NewSecretKey(ecc.BN254)
+	assert.NoError(err)
+
+	// Non-square images, a single row or column, a single pixel and an image of exactly the maximum size
+	for _, size := range [][2]int{{5, 3}, {3, 5}, {1, 6}, {6, 1}, {1, 1}, {4, 5}} {
+		img := newTestImage(assert, size[0], size[1])
+		proof, err := circuits.NewCaptureProof(img, sk, ecc.BN254)
+		assert.NoError(err)
+
+		for quarters := 1; quarters < 4; quarters++ {
+			tr := RotateT{Quarters: quarters}
+			rotated, err := tr.Transform(img)
+			assert.NoError(err)
+
+			// The circuit accepts the natively rotated image as its output
+			assignment, err := tr.NewCircuit(img, rotated, proof, ecc.BN254, maxImageSize)
+			assert.NoError(err)
+			assert.NoError(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()), "%d quarter turns of %dx%d", quarters, size[0], size[1])
+
+			// But not with a channel off by one
+			offByOne := image.Image{Pixels: append([]image.Pixel{}, rotated.Pixels...), Metadata: rotated.Metadata}
+			offByOne.Pixels[len(offByOne.Pixels)-1].G ^= 1
+			assignment, err = tr.NewCircuit(img, offByOne, proof, ecc.BN254, maxImageSize)
+			assert.NoError(err)
+			assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()), "%d quarter turns of %dx%d", quarters, size[0], size[1])
+
+			// Nor the image rotated by another number of quarter turns, small images can be their own rotation
+			other, err := RotateT{Quarters: quarters%3 + 1}.Transform(img)
+			assert.NoError(err)
+			if !slices.Equal(other.Pixels, rotated.Pixels) {
+				other.Metadata = rotated.Metadata
+				assignment, err = tr.NewCircuit(img, other, proof, ecc.BN254, maxImageSize)
+				assert.NoError(err)
+				assert.Error(test.IsSolved(circuit, &assignment, ecc.BN254.ScalarField()), "%d quarter turns of %dx%d", quarters, size[0], size[1])
+			}
+		}
+	}
+
+	// An image larger than the circuit is refused
+	img := newTestImage(assert, 7, 3)
+	proof, err := circuits.NewCaptureProof(img, sk, ecc.BN254)
+	assert.NoError(err)
+	rotated, err := RotateT{Quarters: 1}.Transform(img)
+	assert.NoError(err)
+	_, err = RotateT{Quarters: 1}.NewCircuit(img, rotated, proof, ecc.BN254, maxImageSize)
+	assert.Error(err)
+}
+
+func TestRotationIsPublic(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := circuits.Setup(4, ecc.BN254.ScalarField(), "rotate")
+	assert.NoError(err)
+	prover, err := circuits.NewProver(params)
+	assert.NoError(err)
+	identity, err := circuits.NewCameraIdentity(params.Curve)
+	assert.NoError(err)
+
+	img, err := image.NewImageOfSize("random", 2, 2)
+	assert.NoError(err)
+	proof_in, err := circuits.NewCaptureProof(img, identity.SecKey, params.Curve)
+	assert.NoError(err)
+
+	// A counterclockwise quarter turn is proven as three clockwise ones
+	proof, rotated, err := RotateT{Quarters: -1}.TransformAndProve(prover, img, proof_in)
+	assert.NoError(err)
+	assert.Equal([]int{3}, proof.Parameters)
+
+	valid, err := circuits.Verifier(proof, rotated, params.VerifyingKeys())
+	assert.NoError(err)
+	assert.True(valid)
+
+	// The verifier rejects the proof presented with another number of quarter turns, or none
+	for _, parameters := range [][]int{{1}, {2}, {0}, {7}, nil} {
+		proof.Parameters = parameters
+		_, err = circuits.Verifier(proof, rotated, params.VerifyingKeys())
+		assert.Error(err)
+	}
+}
@@ -0,0 +1,124 @@
+package transformations
+
+import (
+	"errors"
+	"runtime"
+	"src/circuits"
+	"src/image"
+	"sync"
+)
+
+// The crop of a single tile: the signed tile at index Source is cropped by CropT, in the coordinates of the tile.
+type TileCrop struct {
+	Source int
+	CropT
+}
+
+// Split the crop into the crops of the tiles of the grid that intersect the crop area.
+// Return the crop of every output tile, row by row, with the column widths and row heights of the output tiles.
+func (t CropT) TileCrops(columns []int, rows []int) ([]TileCrop, []int, []int, error) {
+	xs, outColumns := cropLengths(columns, t.X0, t.X1)
+	ys, outRows := cropLengths(rows, t.Y0, t.Y1)
+	if t.X0 < 0 || t.Y0 < 0 || xs == nil || ys == nil {
+		return nil, nil, nil, errors.New("INVALID CROP DIMENSIONS: OUT OF THE TILE GRID")
+	}
+
+	var crops []TileCrop
+	for _, y := range ys {
+		for _, x := range xs {
+			crops = append(crops, TileCrop{
+				Source: y.index*len(columns) + x.index,
+				CropT:  CropT{X0: x.start, Y0: y.start, X1: x.end, Y1: y.end},
+			})
+		}
+	}
+
+	return crops, outColumns, outRows, nil
+}
+
+// Crop a picture captured as tiles, proving the crop of every tile concurrently with the prover.
+// The prover's circuits only need to fit a single tile.
+func (t CropT) TransformAndProveTiles(prover *circuits.Prover, tiling image.Tiling, proof_in circuits.TiledProof) (circuits.TiledProof, image.Tiling, error) {
+	crops, columns, rows, err := t.TileCrops(tiling.Columns, tiling.Rows)
+	if err != nil {
+		return circuits.TiledProof{}, image.Tiling{}, err
+	}
+	if len(tiling.Tiles) != len(proof_in.Input_Digests) {
+		return circuits.TiledProof{}, image.Tiling{}, errors.New("TILES DO NOT MATCH THE SIGNED ONES")
+	}
+
+	proof := proof_in
+	proof.Transformation = t.GetType()
+	proof.Output_Columns = columns
+	proof.Output_Rows = rows
+	proof.Sources = make([]int, len(crops))
+	proof.Tiles = make([]circuits.Proof, len(crops))
+	cropped := image.Tiling{Columns: columns, Rows: rows, Tiles: make([]image.Image, len(crops))}
+
+	err = proveTiles(len(crops), func(i int) error {
+		tile_in, err := proof_in.TileInput(crops[i].Source)
+		if err != nil {
+			return err
+		}
+
+		proof.Sources[i] = crops[i].Source
+		proof.Tiles[i], cropped.Tiles[i], err = crops[i].TransformAndProve(prover, tiling.Tiles[crops[i].Source], tile_in)
+		return err
+	})
+	if err != nil {
+		return circuits.TiledProof{}, image.Tiling{}, err
+	}
+
+	return proof, cropped, nil
+}
+
+// The part of a column, or row, of tiles within the crop area, in the coordinates of the tile.
+type tileRange struct {
+	index int
+	start int
+	end   int
+}
+
+// Return the part of every tile within [start, end], and the lengths of these parts.
+// Return nil when the range does not fit in the tiles.
+func cropLengths(lengths []int, start int, end int) ([]tileRange, []int) {
+	var ranges []tileRange
+	var cropped []int
+
+	offset := 0
+	for i, length := range lengths {
+		lo, hi := max(start, offset), min(end, offset+length-1)
+		if lo <= hi {
+			ranges = append(ranges, tileRange{index: i, start: lo - offset, end: hi - offset})
+			cropped = append(cropped, hi-lo+1)
+		}
+		offset += length
+	}
+
+	if start > end || end >= offset {
+		return nil, nil
+	}
+
+	return ranges, cropped
+}
+
+// Run prove for every tile, at most one per processor at a time, and return the errors of all tiles.
+func proveTiles(nbTiles int, prove func(i int) error) error {
+	errs := make([]error, nbTiles)
+	slots := make(chan struct{}, runtime.GOMAXPROCS(0))
+
+	var wg sync.WaitGroup
+	for i := range nbTiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			errs[i] = prove(i)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}